@@ -0,0 +1,98 @@
+// Package bandit implements a Thompson-sampling multi-armed bandit for ranking choices (ads,
+// recommendation slots, ...) by expected reward instead of picking uniformly at random. Each arm
+// keeps a Beta(alpha, beta) posterior over its click-through rate, scoped per "segment" (the
+// category/context the arms were offered under), so an ad that performs well for one product
+// category doesn't crowd out a different one that performs well for another.
+package bandit
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Arm is one option's posterior: alpha successes (plus the Beta(1,1) prior) and beta failures.
+type Arm struct {
+	Alpha float64
+	Beta  float64
+}
+
+// Store persists per-segment, per-arm posteriors. MemoryStore is the only implementation today;
+// the interface exists so a Redis-backed store can be swapped in later without touching Bandit.
+type Store interface {
+	// Get returns the current posterior for (segment, armID), creating it with a flat Beta(1,1)
+	// prior if it doesn't exist yet.
+	Get(ctx context.Context, segment, armID string) (Arm, error)
+	// Update adds reward to alpha and (1-reward) to beta for (segment, armID).
+	Update(ctx context.Context, segment, armID string, reward float64) error
+	// Snapshot returns every arm currently tracked, keyed by segment then arm ID, for
+	// observability (the /debug/bandit endpoint).
+	Snapshot(ctx context.Context) (map[string]map[string]Arm, error)
+}
+
+// Bandit selects arms via Thompson sampling against a Store, falling back to uniform-random
+// selection whenever the store returns an error (e.g. the backing store is unreachable) so a
+// bandit outage never takes ad serving down with it.
+type Bandit struct {
+	store Store
+
+	// mu guards rng: *rand.Rand is documented as unsafe for concurrent use, and SelectArm is
+	// called from every homeHandler/productHandler request, so without this two requests
+	// sampling concurrently would race on rng's internal state.
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// New wraps store in a Bandit. store must be safe for concurrent use.
+func New(store Store) *Bandit {
+	return &Bandit{store: store, rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// SelectArm samples theta_i ~ Beta(alpha_i, beta_i) for every candidate arm in segment and returns
+// the arm with the highest sample. On any store error it falls back to picking uniformly at
+// random among arms, and reports ok=false so callers can log the degraded path.
+func (b *Bandit) SelectArm(ctx context.Context, segment string, arms []string) (arm string, ok bool) {
+	if len(arms) == 0 {
+		return "", false
+	}
+
+	best := ""
+	bestSample := -1.0
+	for _, id := range arms {
+		posterior, err := b.store.Get(ctx, segment, id)
+		if err != nil {
+			return arms[b.randIntn(len(arms))], false
+		}
+		sample := b.sampleBeta(posterior.Alpha, posterior.Beta)
+		if sample > bestSample {
+			bestSample = sample
+			best = id
+		}
+	}
+	return best, true
+}
+
+// randIntn and sampleBeta serialize every use of b.rng behind mu.
+func (b *Bandit) randIntn(n int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.rng.Intn(n)
+}
+
+func (b *Bandit) sampleBeta(alpha, beta float64) float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return sampleBeta(b.rng, alpha, beta)
+}
+
+// RecordReward updates the posterior for (segment, armID) with an observed reward in [0, 1]
+// (typically 1 for a click/add-to-cart/purchase, 0 for an impression with no follow-through).
+func (b *Bandit) RecordReward(ctx context.Context, segment, armID string, reward float64) error {
+	return b.store.Update(ctx, segment, armID, reward)
+}
+
+// Snapshot returns the current posteriors for every tracked (segment, arm), for /debug/bandit.
+func (b *Bandit) Snapshot(ctx context.Context) (map[string]map[string]Arm, error) {
+	return b.store.Snapshot(ctx)
+}
@@ -0,0 +1,105 @@
+package bandit
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// TestSelectArmConvergesToBestArm simulates repeated impressions across arms with different true
+// click-through rates and checks that, after enough rounds to learn, the bandit is choosing the
+// best arm far more often than uniform random would (1/len(arms) of the time).
+func TestSelectArmConvergesToBestArm(t *testing.T) {
+	trueCTR := map[string]float64{"low": 0.05, "mid": 0.15, "best": 0.35}
+	arms := []string{"low", "mid", "best"}
+
+	store := NewMemoryStore(time.Hour, DefaultDecayFactor)
+	b := New(store)
+	rng := rand.New(rand.NewSource(42))
+	ctx := context.Background()
+
+	const rounds = 2000
+	bestPicks := 0
+	for i := 0; i < rounds; i++ {
+		arm, ok := b.SelectArm(ctx, "electronics", arms)
+		if !ok {
+			t.Fatalf("SelectArm reported not-ok with a healthy store")
+		}
+		if arm == "best" {
+			bestPicks++
+		}
+		reward := 0.0
+		if rng.Float64() < trueCTR[arm] {
+			reward = 1.0
+		}
+		if err := b.RecordReward(ctx, "electronics", arm, reward); err != nil {
+			t.Fatalf("RecordReward: %v", err)
+		}
+	}
+
+	uniformShare := 1.0 / float64(len(arms))
+	gotShare := float64(bestPicks) / float64(rounds)
+	if gotShare < uniformShare*1.5 {
+		t.Fatalf("expected the bandit to favor the best arm well above uniform (%.2f), got %.2f", uniformShare, gotShare)
+	}
+}
+
+// TestSelectArmFallsBackToUniformOnStoreError verifies a broken store degrades to random
+// selection (reporting ok=false) instead of an error or a panic.
+func TestSelectArmFallsBackToUniformOnStoreError(t *testing.T) {
+	b := New(errorStore{})
+	arm, ok := b.SelectArm(context.Background(), "segment", []string{"a", "b", "c"})
+	if ok {
+		t.Fatal("expected ok=false when the store errors")
+	}
+	if arm != "a" && arm != "b" && arm != "c" {
+		t.Fatalf("expected a fallback pick among the candidate arms, got %q", arm)
+	}
+}
+
+// TestMemoryStoreDecayPullsTowardPrior checks that decay moves a heavily-updated arm's posterior
+// back toward the flat Beta(1,1) prior after enough elapsed intervals.
+func TestMemoryStoreDecayPullsTowardPrior(t *testing.T) {
+	store := NewMemoryStore(time.Minute, 0.5)
+	fixedNow := time.Now()
+	store.now = func() time.Time { return fixedNow }
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		if err := store.Update(ctx, "seg", "arm", 1); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+	before, err := store.Get(ctx, "seg", "arm")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if before.Alpha != 11 {
+		t.Fatalf("expected alpha=11 before decay, got %v", before.Alpha)
+	}
+
+	fixedNow = fixedNow.Add(5 * time.Minute)
+	after, err := store.Get(ctx, "seg", "arm")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if after.Alpha >= before.Alpha {
+		t.Fatalf("expected decay to shrink alpha toward the prior, got %v -> %v", before.Alpha, after.Alpha)
+	}
+}
+
+type errorStore struct{}
+
+func (errorStore) Get(ctx context.Context, segment, armID string) (Arm, error) {
+	return Arm{}, errUnreachable
+}
+func (errorStore) Update(ctx context.Context, segment, armID string, reward float64) error {
+	return errUnreachable
+}
+func (errorStore) Snapshot(ctx context.Context) (map[string]map[string]Arm, error) {
+	return nil, errUnreachable
+}
+
+var errUnreachable = errors.New("store unreachable")
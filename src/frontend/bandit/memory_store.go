@@ -0,0 +1,105 @@
+package bandit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultDecayInterval and DefaultDecayFactor pull every arm's posterior back toward the flat
+// Beta(1,1) prior over time, so an ad that was popular last month doesn't keep winning against
+// newer content purely on stale counts.
+const (
+	DefaultDecayInterval = time.Hour
+	DefaultDecayFactor   = 0.9
+)
+
+type memoryArm struct {
+	Arm
+	lastDecay time.Time
+}
+
+// MemoryStore is an in-process Store with periodic decay applied lazily on read/write, plus a
+// Snapshot method that returns a copy of every tracked arm. Nothing in this package persists
+// those snapshots, so a MemoryStore's posteriors are lost on restart.
+type MemoryStore struct {
+	mu            sync.Mutex
+	arms          map[string]map[string]*memoryArm
+	decayInterval time.Duration
+	decayFactor   float64
+	now           func() time.Time
+}
+
+// NewMemoryStore constructs a MemoryStore that decays each arm's posterior by decayFactor once per
+// decayInterval of wall-clock time since the arm was first seen (Update does not reset this clock).
+func NewMemoryStore(decayInterval time.Duration, decayFactor float64) *MemoryStore {
+	return &MemoryStore{
+		arms:          make(map[string]map[string]*memoryArm),
+		decayInterval: decayInterval,
+		decayFactor:   decayFactor,
+		now:           time.Now,
+	}
+}
+
+func (s *MemoryStore) armLocked(segment, armID string) *memoryArm {
+	segArms, ok := s.arms[segment]
+	if !ok {
+		segArms = make(map[string]*memoryArm)
+		s.arms[segment] = segArms
+	}
+	a, ok := segArms[armID]
+	if !ok {
+		a = &memoryArm{Arm: Arm{Alpha: 1, Beta: 1}, lastDecay: s.now()}
+		segArms[armID] = a
+	}
+	s.decayLocked(a)
+	return a
+}
+
+// decayLocked pulls a toward the Beta(1,1) prior once per elapsed decayInterval, so long-idle
+// arms don't keep an outsized influence from stale observations.
+func (s *MemoryStore) decayLocked(a *memoryArm) {
+	if s.decayInterval <= 0 {
+		return
+	}
+	elapsed := s.now().Sub(a.lastDecay)
+	periods := int(elapsed / s.decayInterval)
+	if periods <= 0 {
+		return
+	}
+	for i := 0; i < periods; i++ {
+		a.Alpha = 1 + (a.Alpha-1)*s.decayFactor
+		a.Beta = 1 + (a.Beta-1)*s.decayFactor
+	}
+	a.lastDecay = a.lastDecay.Add(time.Duration(periods) * s.decayInterval)
+}
+
+func (s *MemoryStore) Get(ctx context.Context, segment, armID string) (Arm, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.armLocked(segment, armID).Arm, nil
+}
+
+func (s *MemoryStore) Update(ctx context.Context, segment, armID string, reward float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.armLocked(segment, armID)
+	a.Alpha += reward
+	a.Beta += 1 - reward
+	return nil
+}
+
+func (s *MemoryStore) Snapshot(ctx context.Context) (map[string]map[string]Arm, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]map[string]Arm, len(s.arms))
+	for segment, segArms := range s.arms {
+		copySeg := make(map[string]Arm, len(segArms))
+		for armID, a := range segArms {
+			s.decayLocked(a)
+			copySeg[armID] = a.Arm
+		}
+		out[segment] = copySeg
+	}
+	return out, nil
+}
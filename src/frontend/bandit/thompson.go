@@ -0,0 +1,49 @@
+package bandit
+
+import (
+	"math"
+	"math/rand"
+)
+
+// sampleBeta draws a sample from Beta(alpha, beta) via two independent Gamma draws:
+// Beta(a,b) = X / (X+Y) where X ~ Gamma(a,1), Y ~ Gamma(b,1). Go's stdlib has no Beta/Gamma
+// sampler, so this implements Marsaglia-Tsang for the Gamma draws.
+func sampleBeta(rng *rand.Rand, alpha, beta float64) float64 {
+	x := sampleGamma(rng, alpha)
+	y := sampleGamma(rng, beta)
+	if x+y == 0 {
+		return 0.5
+	}
+	return x / (x + y)
+}
+
+// sampleGamma draws a sample from Gamma(shape, 1) using the Marsaglia-Tsang method, boosting
+// shape<1 via Gamma(shape) = Gamma(shape+1) * U^(1/shape) (Gamma distributions near 0 shape need
+// this boost for Marsaglia-Tsang's rejection step to behave).
+func sampleGamma(rng *rand.Rand, shape float64) float64 {
+	if shape < 1 {
+		u := rng.Float64()
+		return sampleGamma(rng, shape+1) * math.Pow(u, 1/shape)
+	}
+
+	d := shape - 1.0/3.0
+	c := 1.0 / math.Sqrt(9*d)
+	for {
+		var x, v float64
+		for {
+			x = rng.NormFloat64()
+			v = 1 + c*x
+			if v > 0 {
+				break
+			}
+		}
+		v = v * v * v
+		u := rng.Float64()
+		if u < 1-0.0331*x*x*x*x {
+			return d * v
+		}
+		if math.Log(u) < 0.5*x*x+d*(1-v+math.Log(v)) {
+			return d * v
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// headerCSRFToken and formFieldCSRFToken are where requireCSRF looks for the token it issues via
+// csrfToken/injectCommonTemplateData's "csrf_token" template value.
+const (
+	headerCSRFToken    = "X-CSRF-Token"
+	formFieldCSRFToken = "_csrf"
+)
+
+// sessionSecret backs both the signed shop_session-id cookie (see signSessionID/verifySessionID)
+// and the CSRF token (see csrfToken): without it, either a forged session cookie or a CSRF token
+// guess would be as easy as copying an unsigned opaque ID, so unlike most of this service's env
+// vars it's required rather than defaulted.
+func sessionSecret() []byte {
+	v := os.Getenv("SESSION_SECRET")
+	if v == "" {
+		panic(`environment variable "SESSION_SECRET" not set`)
+	}
+	return []byte(v)
+}
+
+// signSessionID appends an HMAC-SHA256 tag over id, so verifySessionID can detect a cookie value
+// a client forged or tampered with instead of trusting whatever shop_session-id it presents.
+func signSessionID(id string) string {
+	return id + "." + hex.EncodeToString(hmacTag(sessionSecret(), id))
+}
+
+// verifySessionID splits a signed shop_session-id cookie value back into its id and validates the
+// tag, returning ok=false for anything malformed or tampered with (including a pre-upgrade,
+// unsigned cookie value from before this existed).
+func verifySessionID(value string) (id string, ok bool) {
+	i := strings.LastIndex(value, ".")
+	if i < 0 {
+		return "", false
+	}
+	id, tagHex := value[:i], value[i+1:]
+	tag, err := hex.DecodeString(tagHex)
+	if err != nil {
+		return "", false
+	}
+	if !hmac.Equal(tag, hmacTag(sessionSecret(), id)) {
+		return "", false
+	}
+	return id, true
+}
+
+// csrfToken derives a double-submit CSRF token from sessionID: since it's an HMAC over a value
+// only the server and that session's own cookie holder know implicitly (the signed session
+// cookie itself isn't readable cross-origin), a page on another origin can't compute a valid
+// token for the victim's session even though it can trigger a same-origin cookie-bearing request.
+func csrfToken(sessionID string) string {
+	return hex.EncodeToString(hmacTag(sessionSecret(), "csrf:"+sessionID))
+}
+
+func hmacTag(secret []byte, data string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// requireCSRF rejects any request whose X-CSRF-Token header or _csrf form field doesn't match
+// csrfToken(sessionID(r)) with a 403, so a cross-origin form or fetch that merely carries the
+// victim's session cookie (which browsers attach automatically) can't drive a state-changing
+// route without also knowing a token it was never served. It must sit behind ensureSessionID,
+// since it reads the session ID ensureSessionID installs into the request context.
+func (fe *frontendServer) requireCSRF(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get(headerCSRFToken)
+		if token == "" {
+			token = r.FormValue(formFieldCSRFToken)
+		}
+		want := csrfToken(sessionID(r))
+		if token == "" || !hmac.Equal([]byte(token), []byte(want)) {
+			http.Error(w, "invalid or missing CSRF token", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,104 @@
+package events
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/resilience"
+)
+
+// envSink selects the backend: http (default), kafka, or pubsub.
+const envSink = "BEHAVIOR_EVENT_SINK"
+
+// NewProducerFromEnv builds the EventProducer selected by BEHAVIOR_EVENT_SINK. httpURL and
+// httpClient are only used by the http sink (the legacy PEAU "/track_behavior" endpoint and the
+// shared pooled client from main.go); if the sink is left at its http default and httpURL is
+// empty (PEAU_AGENT_SERVICE_ADDR unset), a NoopProducer is returned instead, matching the old
+// trackBehavior's "skip if PEAU agent not configured" behavior.
+func NewProducerFromEnv(ctx context.Context, httpURL string, httpClient *http.Client, log logrus.FieldLogger) (EventProducer, error) {
+	sinkName := os.Getenv(envSink)
+	if (sinkName == "" || sinkName == "http") && httpURL == "" {
+		return NoopProducer{}, nil
+	}
+
+	sink, err := newSinkFromEnv(ctx, sinkName, httpURL, httpClient, log)
+	if err != nil {
+		return nil, err
+	}
+	return NewProducer(sink, configFromEnv(), log), nil
+}
+
+func newSinkFromEnv(ctx context.Context, sinkName, httpURL string, httpClient *http.Client, log logrus.FieldLogger) (Sink, error) {
+	switch sinkName {
+	case "", "http":
+		policy := resilience.PolicyFromEnv("BEHAVIOR_EVENT")
+		breaker := resilience.NewBreaker(policy.BreakerThreshold, policy.BreakerCooldown)
+		return NewHTTPSink(httpURL, httpClient, breaker), nil
+
+	case "kafka":
+		brokers := strings.Split(os.Getenv("BEHAVIOR_EVENT_KAFKA_BROKERS"), ",")
+		topic := os.Getenv("BEHAVIOR_EVENT_KAFKA_TOPIC")
+		if topic == "" {
+			topic = "behavior-events"
+		}
+		return NewKafkaSink(brokers, topic, log)
+
+	case "pubsub":
+		topic := os.Getenv("BEHAVIOR_EVENT_PUBSUB_TOPIC")
+		if topic == "" {
+			topic = "behavior-events"
+		}
+		return NewPubSubSink(ctx, os.Getenv("BEHAVIOR_EVENT_PUBSUB_PROJECT"), topic)
+
+	default:
+		return nil, errors.Errorf("events: unknown %s %q (want http, kafka, or pubsub)", envSink, sinkName)
+	}
+}
+
+func configFromEnv() Config {
+	cfg := DefaultConfig
+	if v, ok := intEnv("BEHAVIOR_EVENT_BUFFER_SIZE"); ok {
+		cfg.BufferSize = v
+	}
+	if v, ok := intEnv("BEHAVIOR_EVENT_BATCH_SIZE"); ok {
+		cfg.BatchSize = v
+	}
+	if v, ok := durationEnv("BEHAVIOR_EVENT_BATCH_INTERVAL"); ok {
+		cfg.BatchInterval = v
+	}
+	if v, ok := intEnv("BEHAVIOR_EVENT_MAX_RETRIES"); ok {
+		cfg.MaxRetries = v
+	}
+	return cfg
+}
+
+func intEnv(key string) (int, bool) {
+	s := os.Getenv(key)
+	if s == "" {
+		return 0, false
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+func durationEnv(key string) (time.Duration, bool) {
+	s := os.Getenv(key)
+	if s == "" {
+		return 0, false
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, false
+	}
+	return d, true
+}
@@ -0,0 +1,44 @@
+// Package events replaces the old fire-and-forget "POST straight to the PEAU agent" behavior
+// tracking with a buffered, batched EventProducer that can sit in front of an HTTP endpoint, a
+// Kafka topic, or a Pub/Sub topic depending on BEHAVIOR_EVENT_SINK, so downstream consumers (the
+// PEAU agent, analytics) can subscribe to the same event stream independently.
+package events
+
+import (
+	"context"
+	"time"
+)
+
+// Event is the structured behavior-event schema shared by every sink. Field names are part of
+// the wire contract for downstream consumers: add fields freely, don't rename or repurpose one.
+type Event struct {
+	UserID     string    `json:"user_id"`
+	SessionID  string    `json:"session_id"`
+	EventType  string    `json:"event_type"`
+	ProductID  string    `json:"product_id,omitempty"`
+	Categories []string  `json:"categories,omitempty"`
+	Currency   string    `json:"currency,omitempty"`
+	CartSize   int       `json:"cart_size"`
+	Timestamp  time.Time `json:"timestamp"`
+	TraceID    string    `json:"trace_id,omitempty"`
+}
+
+// EventProducer publishes behavior events to whichever sink is configured. Publish must never
+// block the caller on network I/O.
+type EventProducer interface {
+	Publish(ctx context.Context, evt Event) error
+	Close() error
+}
+
+// Sink delivers one already-batched group of events to a backend (HTTP, Kafka, Pub/Sub, ...).
+type Sink interface {
+	SendBatch(ctx context.Context, batch []Event) error
+	Close() error
+}
+
+// NoopProducer discards every event. It's used when no sink is configured at all (the frontend
+// equivalent of the old trackBehavior's "skip if PEAU agent not configured" early return).
+type NoopProducer struct{}
+
+func (NoopProducer) Publish(context.Context, Event) error { return nil }
+func (NoopProducer) Close() error                         { return nil }
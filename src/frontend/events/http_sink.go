@@ -0,0 +1,68 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/resilience"
+)
+
+// HTTPSink POSTs each batch as a JSON object {"events": [...]} of Event values to a single
+// endpoint (the PEAU agent's /track_behavior, by default), for consumers that haven't migrated to
+// Kafka/Pub/Sub. The body keeps the old trackBehavior payload's top-level "events" array, but its
+// entries use Event's field names and there is no top-level user_id. breaker is the same per-destination circuit breaker
+// trackBehavior used to guard the PEAU call directly with; it now guards the sink instead, so a
+// down PEAU agent trips the breaker and the producer's own retry loop stops hammering it.
+type HTTPSink struct {
+	url     string
+	client  *http.Client
+	breaker *resilience.Breaker
+}
+
+// NewHTTPSink posts to url using client, which callers should point at a shared, pooled
+// *http.Client (see httpClientWithTimeout in main.go) rather than a fresh one per sink. breaker
+// may be nil, which disables circuit breaking.
+func NewHTTPSink(url string, client *http.Client, breaker *resilience.Breaker) *HTTPSink {
+	return &HTTPSink{url: url, client: client, breaker: breaker}
+}
+
+func (s *HTTPSink) SendBatch(ctx context.Context, batch []Event) error {
+	if s.breaker != nil && !s.breaker.Allow() {
+		return errors.New("events: circuit breaker open for http sink")
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"events": batch})
+	if err != nil {
+		return errors.Wrap(err, "events: failed to marshal batch")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "events: failed to build request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if s.breaker != nil {
+			s.breaker.Failure()
+		}
+		return errors.Wrap(err, "events: failed to post batch")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		if s.breaker != nil {
+			s.breaker.Failure()
+		}
+		return errors.Errorf("events: sink returned status %d", resp.StatusCode)
+	}
+	if s.breaker != nil {
+		s.breaker.Success()
+	}
+	return nil
+}
+
+func (s *HTTPSink) Close() error { return nil }
@@ -0,0 +1,64 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/IBM/sarama"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// KafkaSink publishes each event as its own message, keyed by user ID so a downstream consumer
+// partitioned by key sees one user's events in order, via an AsyncProducer whose delivery errors
+// are logged rather than returned (SendBatch only waits for the messages to be accepted onto the
+// producer's internal channel, not for broker acks).
+type KafkaSink struct {
+	producer sarama.AsyncProducer
+	topic    string
+}
+
+// NewKafkaSink dials brokers and starts a goroutine that logs async delivery errors.
+func NewKafkaSink(brokers []string, topic string, log logrus.FieldLogger) (*KafkaSink, error) {
+	cfg := sarama.NewConfig()
+	cfg.Producer.RequiredAcks = sarama.WaitForLocal
+	cfg.Producer.Return.Successes = false
+	cfg.Producer.Return.Errors = true
+
+	producer, err := sarama.NewAsyncProducer(brokers, cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "events: failed to create kafka producer")
+	}
+
+	s := &KafkaSink{producer: producer, topic: topic}
+	go func() {
+		for err := range producer.Errors() {
+			log.WithError(err).Warn("events: kafka delivery failed")
+		}
+	}()
+	return s, nil
+}
+
+func (s *KafkaSink) SendBatch(ctx context.Context, batch []Event) error {
+	for _, evt := range batch {
+		payload, err := json.Marshal(evt)
+		if err != nil {
+			return errors.Wrap(err, "events: failed to marshal event")
+		}
+		msg := &sarama.ProducerMessage{
+			Topic: s.topic,
+			Key:   sarama.StringEncoder(evt.UserID),
+			Value: sarama.ByteEncoder(payload),
+		}
+		select {
+		case s.producer.Input() <- msg:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (s *KafkaSink) Close() error {
+	return s.producer.Close()
+}
@@ -0,0 +1,14 @@
+package events
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// droppedTotal counts events dropped because Producer's buffer was full (see Producer.Publish).
+// Scrape it to alert on a downstream sink (PEAU agent, Kafka, Pub/Sub) that's falling behind.
+var droppedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "frontend_behavior_events_dropped_total",
+	Help: "Behavior events dropped because the publish buffer was full.",
+})
+
+func init() {
+	prometheus.MustRegister(droppedTotal)
+}
@@ -0,0 +1,135 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Config controls the buffering/batching/retry policy shared by every sink.
+type Config struct {
+	BufferSize     int
+	BatchSize      int
+	BatchInterval  time.Duration
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+}
+
+// DefaultConfig is used for any field configFromEnv doesn't find set.
+var DefaultConfig = Config{
+	BufferSize:     1000,
+	BatchSize:      20,
+	BatchInterval:  2 * time.Second,
+	MaxRetries:     3,
+	RetryBaseDelay: 200 * time.Millisecond,
+}
+
+// Producer is the EventProducer every sink shares: it owns the bounded event channel, the
+// size/time batch trigger, and the retry-with-backoff loop around Sink.SendBatch.
+type Producer struct {
+	sink Sink
+	cfg  Config
+	log  logrus.FieldLogger
+
+	events chan Event
+	done   chan struct{}
+	closed chan struct{}
+}
+
+// NewProducer starts the background batching loop immediately; call Close to flush and stop it.
+func NewProducer(sink Sink, cfg Config, log logrus.FieldLogger) *Producer {
+	p := &Producer{
+		sink:   sink,
+		cfg:    cfg,
+		log:    log,
+		events: make(chan Event, cfg.BufferSize),
+		done:   make(chan struct{}),
+		closed: make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// Publish enqueues evt for the next batch and never blocks: behavior tracking is best-effort and
+// must not slow down the request that triggered it. If the buffer is full, the event is dropped
+// and droppedTotal is incremented instead.
+func (p *Producer) Publish(ctx context.Context, evt Event) error {
+	select {
+	case p.events <- evt:
+		return nil
+	default:
+		droppedTotal.Inc()
+		p.log.WithField("event_type", evt.EventType).Warn("events: buffer full, dropping event")
+		return nil
+	}
+}
+
+// Close stops accepting new events, flushes whatever is buffered through the sink, and closes the
+// sink. It blocks until the final flush completes, so it should run from the SIGTERM handler
+// before the process exits (see main.go), not from a deferred call that might never run.
+func (p *Producer) Close() error {
+	close(p.done)
+	<-p.closed
+	return p.sink.Close()
+}
+
+func (p *Producer) run() {
+	defer close(p.closed)
+	ticker := time.NewTicker(p.cfg.BatchInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, p.cfg.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		p.sendWithRetry(batch)
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case evt := <-p.events:
+			batch = append(batch, evt)
+			if len(batch) >= p.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-p.done:
+			// Drain whatever is already queued, then do one final flush.
+			for drained := false; !drained; {
+				select {
+				case evt := <-p.events:
+					batch = append(batch, evt)
+				default:
+					drained = true
+				}
+			}
+			flush()
+			return
+		}
+	}
+}
+
+func (p *Producer) sendWithRetry(batch []Event) {
+	cp := make([]Event, len(batch))
+	copy(cp, batch)
+
+	delay := p.cfg.RetryBaseDelay
+	for attempt := 0; attempt <= p.cfg.MaxRetries; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := p.sink.SendBatch(ctx, cp)
+		cancel()
+		if err == nil {
+			return
+		}
+		if attempt == p.cfg.MaxRetries {
+			p.log.WithError(err).WithField("batch_size", len(cp)).Warn("events: giving up on batch after retries")
+			return
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
@@ -0,0 +1,147 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var errFakeSinkFailure = errors.New("fake sink failure")
+
+// fakeSink records every batch it receives and can be made to fail the first N calls, so tests
+// can exercise Producer's retry loop without a real backend.
+type fakeSink struct {
+	mu        sync.Mutex
+	batches   [][]Event
+	failUntil int
+	calls     int
+	closed    bool
+}
+
+func (s *fakeSink) SendBatch(ctx context.Context, batch []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.calls <= s.failUntil {
+		return errFakeSinkFailure
+	}
+	cp := make([]Event, len(batch))
+	copy(cp, batch)
+	s.batches = append(s.batches, cp)
+	return nil
+}
+
+func (s *fakeSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *fakeSink) totalEvents() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, b := range s.batches {
+		n += len(b)
+	}
+	return n
+}
+
+func testLogger() logrus.FieldLogger {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return log
+}
+
+func TestProducerFlushesOnBatchSize(t *testing.T) {
+	sink := &fakeSink{}
+	cfg := Config{BufferSize: 10, BatchSize: 3, BatchInterval: time.Hour, MaxRetries: 0, RetryBaseDelay: time.Millisecond}
+	p := NewProducer(sink, cfg, testLogger())
+	defer p.Close()
+
+	for i := 0; i < 3; i++ {
+		if err := p.Publish(context.Background(), Event{EventType: "x"}); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for sink.totalEvents() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := sink.totalEvents(); got != 3 {
+		t.Fatalf("expected 3 events flushed by batch-size trigger, got %d", got)
+	}
+}
+
+func TestProducerDropsWhenBufferFull(t *testing.T) {
+	sink := &fakeSink{}
+	// A one-slot buffer and a batch interval long enough that no flush happens during the test, so
+	// a burst of Publish calls can find the buffer full. run() still drains the channel into its
+	// batch concurrently, so how many events get dropped is not fixed; the test only checks that
+	// Publish never blocks.
+	cfg := Config{BufferSize: 1, BatchSize: 100, BatchInterval: time.Hour, MaxRetries: 0, RetryBaseDelay: time.Millisecond}
+	p := NewProducer(sink, cfg, testLogger())
+	defer p.Close()
+
+	// Give the run() goroutine a moment to start before publishing.
+	time.Sleep(10 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			_ = p.Publish(context.Background(), Event{EventType: "x"})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked instead of dropping once the buffer filled")
+	}
+}
+
+func TestProducerRetriesThenSucceeds(t *testing.T) {
+	sink := &fakeSink{failUntil: 2}
+	cfg := Config{BufferSize: 10, BatchSize: 1, BatchInterval: time.Hour, MaxRetries: 3, RetryBaseDelay: time.Millisecond}
+	p := NewProducer(sink, cfg, testLogger())
+	defer p.Close()
+
+	if err := p.Publish(context.Background(), Event{EventType: "retry-me"}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for sink.totalEvents() < 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := sink.totalEvents(); got != 1 {
+		t.Fatalf("expected the event to eventually succeed after retries, got %d events", got)
+	}
+}
+
+func TestProducerCloseFlushesAndClosesSink(t *testing.T) {
+	sink := &fakeSink{}
+	cfg := Config{BufferSize: 10, BatchSize: 100, BatchInterval: time.Hour, MaxRetries: 0, RetryBaseDelay: time.Millisecond}
+	p := NewProducer(sink, cfg, testLogger())
+
+	if err := p.Publish(context.Background(), Event{EventType: "final"}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if sink.totalEvents() != 1 {
+		t.Fatalf("expected Close to flush the pending event, got %d", sink.totalEvents())
+	}
+	if !sink.closed {
+		t.Fatal("expected Close to close the underlying sink")
+	}
+}
@@ -0,0 +1,46 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/pkg/errors"
+)
+
+// PubSubSink publishes each event as its own Pub/Sub message, relying on the client library's own
+// batching and flow control rather than duplicating it here.
+type PubSubSink struct {
+	client *pubsub.Client
+	topic  *pubsub.Topic
+}
+
+func NewPubSubSink(ctx context.Context, projectID, topicID string) (*PubSubSink, error) {
+	client, err := pubsub.NewClient(ctx, projectID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "events: failed to create pubsub client for project %s", projectID)
+	}
+	return &PubSubSink{client: client, topic: client.Topic(topicID)}, nil
+}
+
+func (s *PubSubSink) SendBatch(ctx context.Context, batch []Event) error {
+	results := make([]*pubsub.PublishResult, 0, len(batch))
+	for _, evt := range batch {
+		payload, err := json.Marshal(evt)
+		if err != nil {
+			return errors.Wrap(err, "events: failed to marshal event")
+		}
+		results = append(results, s.topic.Publish(ctx, &pubsub.Message{Data: payload}))
+	}
+	for _, res := range results {
+		if _, err := res.Get(ctx); err != nil {
+			return errors.Wrap(err, "events: failed to publish to pubsub")
+		}
+	}
+	return nil
+}
+
+func (s *PubSubSink) Close() error {
+	s.topic.Stop()
+	return s.client.Close()
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"github.com/sony/gobreaker"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/resilience"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/telemetry"
+)
+
+// grpcBreakerState reports each dialed backend's sony/gobreaker state (0=closed, 1=half-open,
+// 2=open, matching gobreaker.State's own numbering) so a dashboard can alert on a backend tripping
+// without scraping logs.
+var grpcBreakerState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "frontend_grpc_breaker_state",
+	Help: "Circuit breaker state per gRPC backend (0=closed, 1=half-open, 2=open).",
+}, []string{"backend"})
+
+func init() {
+	prometheus.MustRegister(grpcBreakerState)
+}
+
+// keepaliveParams applies a modest keepalive ping to every dialed backend, so a dead TCP
+// connection (e.g. behind a load balancer that silently drops idle connections) is detected and
+// re-established instead of surfacing as a hung RPC.
+var keepaliveParams = keepalive.ClientParameters{
+	Time:                30 * time.Second,
+	Timeout:             10 * time.Second,
+	PermitWithoutStream: true,
+}
+
+// dialBackend replaces the old mustConnGRPC: it dials addr lazily (grpc.DialContext without
+// grpc.WithBlock is already non-blocking and reconnects under the hood) and never panics. If the
+// dial itself errors out — which, absent grpc.WithBlock, only happens for a static
+// misconfiguration like a bad TLS cert, not a backend that's merely unreachable yet — it logs and
+// keeps retrying on a background goroutine instead of taking the process down, so the frontend can
+// still serve whatever doesn't depend on that backend (e.g. a cached home page while peau-agent or
+// video-generation is still starting up).
+//
+// name identifies the backend for the breaker gauge and logs (e.g. "checkout"); envPrefix is the
+// existing *_SERVICE prefix used to read timeout/retry/breaker tuning (e.g. "CHECKOUT_SERVICE").
+func dialBackend(ctx context.Context, conn **grpc.ClientConn, name, addr, envPrefix string, log logrus.FieldLogger) {
+	policy := resilience.PolicyFromEnv(envPrefix)
+	breaker := newBackendBreaker(name, policy)
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(tlsCredentialsFromEnv()),
+		grpc.WithKeepaliveParams(keepaliveParams),
+		grpc.WithDefaultServiceConfig(retryServiceConfig(policy, retryableBackend(name))),
+		telemetry.DialOption(),
+		grpc.WithChainUnaryInterceptor(breakerUnaryClientInterceptor(breaker, policy.Timeout)),
+	}
+
+	cc, err := grpc.DialContext(ctx, addr, opts...)
+	if err != nil {
+		log.WithError(err).WithField("backend", name).Error("grpc: initial dial failed, retrying in background")
+		go redialBackend(conn, name, addr, opts, policy, log)
+		return
+	}
+	*conn = cc
+}
+
+// redialBackend keeps retrying dialBackend's dial with exponential backoff until it succeeds,
+// then installs the resulting conn. Callers that read *conn before this completes (there are none
+// today, since main() doesn't use a backend's client until after dialBackend returns) would still
+// see a nil conn; this only covers the rare dial-time failure case described on dialBackend.
+func redialBackend(conn **grpc.ClientConn, name, addr string, opts []grpc.DialOption, policy resilience.Policy, log logrus.FieldLogger) {
+	for attempt := 0; ; attempt++ {
+		time.Sleep(resilience.Backoff(attempt, policy.BaseDelay, policy.MaxDelay))
+
+		cc, err := grpc.DialContext(context.Background(), addr, opts...)
+		if err != nil {
+			log.WithError(err).WithField("backend", name).Warn("grpc: retry dial failed")
+			continue
+		}
+		*conn = cc
+		log.WithField("backend", name).Info("grpc: backend dial recovered")
+		return
+	}
+}
+
+// newBackendBreaker wraps policy's breaker tuning in a sony/gobreaker.CircuitBreaker, publishing
+// every state transition to grpcBreakerState so the breaker's health is visible on /metrics
+// without grepping logs for trip/reset events.
+func newBackendBreaker(name string, policy resilience.Policy) *gobreaker.CircuitBreaker {
+	return gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        name,
+		MaxRequests: 1,
+		Timeout:     policy.BreakerCooldown,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			return counts.ConsecutiveFailures >= uint32(policy.BreakerThreshold)
+		},
+		OnStateChange: func(_ string, _ gobreaker.State, to gobreaker.State) {
+			grpcBreakerState.WithLabelValues(name).Set(float64(to))
+		},
+	})
+}
+
+// breakerUnaryClientInterceptor applies timeout as a per-call deadline and routes every call
+// through breaker, so a tripped breaker fails fast instead of piling up against an already-down
+// backend. Retries are left to the gRPC service config installed by retryServiceConfig, not
+// repeated here, to avoid retrying twice over.
+func breakerUnaryClientInterceptor(breaker *gobreaker.CircuitBreaker, timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		_, err := breaker.Execute(func() (interface{}, error) {
+			callCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return nil, invoker(callCtx, method, req, reply, cc, opts...)
+		})
+		return err
+	}
+}
+
+// retryableBackend reports whether dialBackend should install a retrying service config for the
+// named backend. "checkout" is excluded: CheckoutService/PlaceOrder isn't idempotent (it places an
+// order and charges payment), so transparently retrying it on DEADLINE_EXCEEDED would risk a
+// double-submit that idempotency.Guard only dedupes at the HTTP-handler layer, not here.
+func retryableBackend(name string) bool {
+	return name != "checkout"
+}
+
+// retryServiceConfig builds a gRPC service config (see
+// https://github.com/grpc/grpc/blob/master/doc/service_config.md). When retryable, it applies
+// policy's retry budget to every method on the backend via gRPC's own retry machinery, so a
+// retried attempt is transparent to the caller (unlike resilience.UnaryClientInterceptor's
+// retries, which re-invoke the interceptor chain) and counts against gRPC's hedging/retry
+// throttle. When retryable is false, it installs an empty method config instead, so the backend's
+// calls are never silently retried regardless of status code.
+func retryServiceConfig(policy resilience.Policy, retryable bool) string {
+	if !retryable {
+		return `{"methodConfig": []}`
+	}
+	return fmt.Sprintf(`{
+		"methodConfig": [{
+			"name": [{}],
+			"retryPolicy": {
+				"MaxAttempts": %d,
+				"InitialBackoff": "%.6fs",
+				"MaxBackoff": "%.6fs",
+				"BackoffMultiplier": 2,
+				"RetryableStatusCodes": ["UNAVAILABLE", "DEADLINE_EXCEEDED"]
+			}
+		}]
+	}`, policy.MaxAttempts, policy.BaseDelay.Seconds(), policy.MaxDelay.Seconds())
+}
+
+// tlsCredentialsFromEnv builds transport credentials for every dialed backend from GRPC_TLS_CA
+// (server CA, for verifying the backend's certificate), GRPC_TLS_CERT and GRPC_TLS_KEY (client
+// cert/key, for mTLS). All three are optional; with none set it falls back to a plaintext
+// connection, matching mustConnGRPC's old grpc.WithInsecure() default for local/cluster-internal
+// traffic that doesn't terminate TLS itself.
+func tlsCredentialsFromEnv() credentials.TransportCredentials {
+	caFile := os.Getenv("GRPC_TLS_CA")
+	certFile := os.Getenv("GRPC_TLS_CERT")
+	keyFile := os.Getenv("GRPC_TLS_KEY")
+
+	if caFile == "" && certFile == "" && keyFile == "" {
+		return insecure.NewCredentials()
+	}
+
+	cfg := &tls.Config{}
+
+	if caFile != "" {
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			logrus.WithError(err).WithField("file", caFile).Fatal("grpc: failed to read GRPC_TLS_CA")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			logrus.WithField("file", caFile).Fatal("grpc: GRPC_TLS_CA did not contain a valid PEM certificate")
+		}
+		cfg.RootCAs = pool
+	}
+
+	if certFile != "" && keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			logrus.WithError(err).Fatal("grpc: failed to load GRPC_TLS_CERT/GRPC_TLS_KEY")
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return credentials.NewTLS(cfg)
+}
@@ -2,6 +2,9 @@ package main
 
 import (
 	"context"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"html/template"
@@ -10,6 +13,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -17,12 +21,23 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 
 	"bytes"
 	"mime/multipart"
 
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/events"
 	pb "github.com/GoogleCloudPlatform/microservices-demo/src/frontend/genproto"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/httpx"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/idempotency"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/middleware"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/moderation"
 	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/money"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/packaging"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/productsearch"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/sse"
 	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/validator"
 )
 
@@ -46,82 +61,32 @@ var (
 
 var validEnvs = []string{"local", "gcp", "azure", "aws", "onprem", "alibaba"}
 
-// trackBehavior sends user behavior events to the PEAU Agent for proactive engagement
-func (fe *frontendServer) trackBehavior(ctx context.Context, userID string, eventType string, productID string) {
-	if fe.peauAgentSvcAddr == "" {
-		return // Skip if PEAU agent not configured
+// traceIDFromContext returns the active OpenTelemetry trace ID, if any, so behavior events can
+// be correlated back to the request that produced them.
+func traceIDFromContext(ctx context.Context) string {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.HasTraceID() {
+		return ""
 	}
+	return spanCtx.TraceID().String()
+}
 
-	event := map[string]interface{}{
-		"user_id": userID,
-		"events": []map[string]interface{}{
-			{
-				"type":       eventType,
-				"product_id": productID,
-				"timestamp":  time.Now().Format(time.RFC3339),
-			},
-		},
-	}
-
-	reqBody, err := json.Marshal(event)
-	if err != nil {
-		return // Skip on marshal error
-	}
-
-	peauURL := "http://" + fe.peauAgentSvcAddr + "/track_behavior"
-
-	// Get session ID from context for notification storage
-	sessionID := ctx.Value(ctxKeySessionID{}).(string)
-
-	// Send asynchronously to avoid blocking the main request
-	go func() {
-		client := &http.Client{Timeout: 20 * time.Second}
-		resp, err := client.Post(peauURL, "application/json", strings.NewReader(string(reqBody)))
-		if err != nil {
-			// Log error but don't fail the main request
-			log := logrus.WithField("service", "peau-agent")
-			log.WithError(err).Warn("failed to track behavior")
-			return
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			log := logrus.WithField("service", "peau-agent")
-			log.WithField("status", resp.StatusCode).Warn("behavior tracking returned non-200 status")
-			return
-		}
-
-		// Read and parse the response to check for notifications
-		respBody, err := io.ReadAll(resp.Body)
-		log.WithField("response", string(respBody)).Info("PEAU agent response")
-		if err != nil {
-			log := logrus.WithField("service", "peau-agent")
-			log.WithError(err).Warn("failed to read PEAU agent response")
-			return
-		}
-
-		var peauResponse map[string]interface{}
-		if err := json.Unmarshal(respBody, &peauResponse); err != nil {
-			log := logrus.WithField("service", "peau-agent")
-			log.WithError(err).Warn("failed to parse PEAU agent response")
+// trackBehavior publishes a behavior event onto fe.events (see the events package), replacing the
+// old direct POST to the PEAU agent. Publishing is non-blocking and best-effort: Publish buffers,
+// batches, and retries on the caller's behalf, so this never slows down the handler it's called
+// from.
+func (fe *frontendServer) trackBehavior(ctx context.Context, evt events.Event) {
+	if fe.rateLimiter != nil {
+		if allowed, _, err := fe.rateLimiter.Allow(ctx, middleware.ClassTrackBehavior, evt.SessionID, ""); err == nil && !allowed {
 			return
 		}
+	}
 
-		// Check if there's a suggestion in the response
-		if suggestionData, exists := peauResponse["suggestion_data"]; exists && suggestionData != nil {
-			if suggestionMap, ok := suggestionData.(map[string]interface{}); ok {
-				if message, exists := suggestionMap["suggestion"]; exists {
-					if messageStr, ok := message.(string); ok && messageStr != "" {
-						// Store the notification
-						fe.notifications.AddNotification(sessionID, userID, messageStr)
-
-						log := logrus.WithField("service", "peau-agent")
-						log.WithField("user_id", userID).WithField("session_id", sessionID).Info("stored PEAU agent suggestion as notification")
-					}
-				}
-			}
-		}
-	}()
+	evt.Timestamp = time.Now()
+	evt.TraceID = traceIDFromContext(ctx)
+	if err := fe.events.Publish(ctx, evt); err != nil {
+		logrus.WithField("service", "events").WithError(err).Warn("failed to publish behavior event")
+	}
 }
 
 func (fe *frontendServer) homeHandler(w http.ResponseWriter, r *http.Request) {
@@ -227,9 +192,6 @@ func (fe *frontendServer) productHandler(w http.ResponseWriter, r *http.Request)
 	log.WithField("id", id).WithField("currency", currentCurrency(r)).
 		Debug("serving product page")
 
-	// Track product view behavior for PEAU Agent
-	fe.trackBehavior(r.Context(), sessionID(r), "product_viewed", id)
-
 	p, err := fe.getProduct(r.Context(), id)
 	if err != nil {
 		renderHTTPError(log, r, w, errors.Wrap(err, "could not retrieve product"), http.StatusInternalServerError)
@@ -247,6 +209,16 @@ func (fe *frontendServer) productHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	fe.trackBehavior(r.Context(), events.Event{
+		UserID:     sessionID(r),
+		SessionID:  sessionID(r),
+		EventType:  "product_viewed",
+		ProductID:  id,
+		Categories: p.GetCategories(),
+		Currency:   currentCurrency(r),
+		CartSize:   cartSize(cart),
+	})
+
 	price, err := fe.convertCurrency(r.Context(), p.GetPriceUsd(), currentCurrency(r))
 	if err != nil {
 		renderHTTPError(log, r, w, errors.Wrap(err, "failed to convert currency"), http.StatusInternalServerError)
@@ -264,13 +236,15 @@ func (fe *frontendServer) productHandler(w http.ResponseWriter, r *http.Request)
 		Price *pb.Money
 	}{p, price}
 
-	// Fetch packaging info (weight/dimensions) of the product
-	// The packaging service is an optional microservice you can run as part of a Google Cloud demo.
+	// Fetch packaging info (weight/dimensions) of the product. Prefers the packaging service's
+	// gRPC stream (see frontend/packaging) and falls back to the legacy HTTP lookup.
 	var packagingInfo *PackagingInfo = nil
 	if isPackagingServiceConfigured() {
-		packagingInfo, err = httpGetPackagingInfo(id)
+		info, err := fe.packagingClient.Get(r.Context(), id)
 		if err != nil {
 			fmt.Println("Failed to obtain product's packaging info:", err)
+		} else {
+			packagingInfo = &PackagingInfo{Weight: info.Weight, Width: info.Width, Height: info.Height, Depth: info.Depth}
 		}
 	}
 
@@ -312,8 +286,20 @@ func (fe *frontendServer) addToCartHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Track add to cart behavior for PEAU Agent
-	fe.trackBehavior(r.Context(), sessionID(r), "item_added_to_cart", payload.ProductID)
+	cart, err := fe.getCart(r.Context(), sessionID(r))
+	if err != nil {
+		log.WithField("error", err).Warn("failed to get cart for behavior tracking")
+	}
+
+	fe.trackBehavior(r.Context(), events.Event{
+		UserID:     sessionID(r),
+		SessionID:  sessionID(r),
+		EventType:  "item_added_to_cart",
+		ProductID:  payload.ProductID,
+		Categories: p.GetCategories(),
+		Currency:   currentCurrency(r),
+		CartSize:   cartSize(cart),
+	})
 
 	w.Header().Set("location", baseUrl+"/cart")
 	w.WriteHeader(http.StatusFound)
@@ -358,12 +344,21 @@ func (fe *frontendServer) viewCartHandler(w http.ResponseWriter, r *http.Request
 	}
 
 	type cartItemView struct {
-		Item     *pb.Product
-		Quantity int32
-		Price    *pb.Money
+		Item          *pb.Product
+		Quantity      int32
+		Price         *pb.Money
+		PackagingInfo *PackagingInfo
 	}
 	items := make([]cartItemView, len(cart))
 	totalPrice := pb.Money{CurrencyCode: currentCurrency(r)}
+
+	// Resolve packaging info for every cart line in one batched gRPC stream call instead of one
+	// HTTP round trip per item.
+	var packagingByProduct map[string]*packaging.Info
+	if isPackagingServiceConfigured() {
+		packagingByProduct, _ = fe.packagingClient.GetPackagingInfoBatch(r.Context(), cartIDs(cart))
+	}
+
 	for i, item := range cart {
 		p, err := fe.getProduct(r.Context(), item.GetProductId())
 		if err != nil {
@@ -377,10 +372,15 @@ func (fe *frontendServer) viewCartHandler(w http.ResponseWriter, r *http.Request
 		}
 
 		multPrice := money.MultiplySlow(*price, uint32(item.GetQuantity()))
-		items[i] = cartItemView{
+		view := cartItemView{
 			Item:     p,
 			Quantity: item.GetQuantity(),
-			Price:    &multPrice}
+			Price:    &multPrice,
+		}
+		if info, ok := packagingByProduct[item.GetProductId()]; ok {
+			view.PackagingInfo = &PackagingInfo{Weight: info.Weight, Width: info.Width, Height: info.Height, Depth: info.Depth}
+		}
+		items[i] = view
 		totalPrice = money.Must(money.Sum(totalPrice, multPrice))
 	}
 	totalPrice = money.Must(money.Sum(totalPrice, *shippingCost))
@@ -434,7 +434,52 @@ func (fe *frontendServer) placeOrderHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	order, err := pb.NewCheckoutServiceClient(fe.checkoutSvcConn).
+	if fe.idempotency == nil {
+		fe.placeOrder(w, r, log, payload)
+		return
+	}
+
+	key, generated := idempotencyKeyFor(r)
+	result, outcome, err := fe.idempotency.Run(r.Context(), sessionID(r), key, payloadHash(payload), func() (idempotency.Result, error) {
+		buf := &bytes.Buffer{}
+		if err := fe.renderOrder(buf, r, log, payload); err != nil {
+			return idempotency.Result{}, err
+		}
+		return idempotency.Result{StatusCode: http.StatusOK, ContentType: "text/html; charset=utf-8", Body: buf.Bytes()}, nil
+	})
+	if err != nil {
+		renderHTTPError(log, r, w, err, http.StatusInternalServerError)
+		return
+	}
+	if outcome == idempotency.Conflict {
+		renderHTTPError(log, r, w, errors.New("a different order was already submitted with this idempotency key"), http.StatusConflict)
+		return
+	}
+	if outcome == idempotency.InProgress {
+		renderHTTPError(log, r, w, errors.New("this order is still being processed, please retry shortly"), http.StatusConflict)
+		return
+	}
+	if generated {
+		w.Header().Set(idempotencyKeyHeader, key)
+	}
+	w.Header().Set("Content-Type", result.ContentType)
+	w.WriteHeader(result.StatusCode)
+	_, _ = w.Write(result.Body)
+}
+
+// placeOrder runs the checkout flow directly against w, with no idempotency protection. Used when
+// fe.idempotency isn't configured (IDEMPOTENCY_REDIS_ADDR unset).
+func (fe *frontendServer) placeOrder(w http.ResponseWriter, r *http.Request, log logrus.FieldLogger, payload validator.PlaceOrderPayload) {
+	if err := fe.renderOrder(w, r, log, payload); err != nil {
+		renderHTTPError(log, r, w, err, http.StatusInternalServerError)
+	}
+}
+
+// renderOrder places the order described by payload and writes the resulting "order" template to
+// w. It's shared by placeOrder and placeOrderHandler's idempotency-guarded path, the latter
+// rendering into a buffer first so the response can be cached for replay.
+func (fe *frontendServer) renderOrder(w io.Writer, r *http.Request, log logrus.FieldLogger, payload validator.PlaceOrderPayload) error {
+	order, err := fe.checkoutClient.
 		PlaceOrder(r.Context(), &pb.PlaceOrderRequest{
 			Email: payload.Email,
 			CreditCard: &pb.CreditCardInfo{
@@ -452,8 +497,7 @@ func (fe *frontendServer) placeOrderHandler(w http.ResponseWriter, r *http.Reque
 				Country:       payload.Country},
 		})
 	if err != nil {
-		renderHTTPError(log, r, w, errors.Wrap(err, "failed to complete the order"), http.StatusInternalServerError)
-		return
+		return errors.Wrap(err, "failed to complete the order")
 	}
 	log.WithField("order", order.GetOrder().GetOrderId()).Info("order placed")
 
@@ -468,19 +512,45 @@ func (fe *frontendServer) placeOrderHandler(w http.ResponseWriter, r *http.Reque
 
 	currencies, err := fe.getCurrencies(r.Context())
 	if err != nil {
-		renderHTTPError(log, r, w, errors.Wrap(err, "could not retrieve currencies"), http.StatusInternalServerError)
-		return
+		return errors.Wrap(err, "could not retrieve currencies")
 	}
 
-	if err := templates.ExecuteTemplate(w, "order", injectCommonTemplateData(r, map[string]interface{}{
+	return templates.ExecuteTemplate(w, "order", injectCommonTemplateData(r, map[string]interface{}{
 		"show_currency":   false,
 		"currencies":      currencies,
 		"order":           order.GetOrder(),
 		"total_paid":      &totalPaid,
 		"recommendations": recommendations,
-	})); err != nil {
-		log.Println(err)
+	}))
+}
+
+// idempotencyKeyHeader is the client-supplied header placeOrderHandler uses to detect a duplicate
+// submit. If the client omits it, one is generated and echoed back in the response so a retry can
+// reuse it.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+func idempotencyKeyFor(r *http.Request) (key string, generated bool) {
+	if k := r.Header.Get(idempotencyKeyHeader); k != "" {
+		return k, false
 	}
+	return generateIdempotencyKey(), true
+}
+
+func generateIdempotencyKey() string {
+	b := make([]byte, 16)
+	if _, err := cryptorand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+// payloadHash fingerprints the fields that define an order, so a repeat request under the same
+// Idempotency-Key but with different contents can be rejected with a 409 instead of silently
+// replaying the original order.
+func payloadHash(payload validator.PlaceOrderPayload) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%+v", payload)
+	return hex.EncodeToString(h.Sum(nil))
 }
 
 func (fe *frontendServer) assistantHandler(w http.ResponseWriter, r *http.Request) {
@@ -531,6 +601,14 @@ func (fe *frontendServer) getProductByID(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusOK)
 }
 
+// sseFlushInterval bounds how long an event can sit buffered before chatStreamHandler flushes it;
+// sseKeepaliveInterval governs how often a ": keepalive" comment is sent on an otherwise idle
+// stream, so intermediaries (proxies, load balancers) don't drop the connection as dead.
+const (
+	sseFlushInterval     = 100 * time.Millisecond
+	sseKeepaliveInterval = 15 * time.Second
+)
+
 func (fe *frontendServer) chatStreamHandler(w http.ResponseWriter, r *http.Request) {
 	log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
 
@@ -549,55 +627,100 @@ func (fe *frontendServer) chatStreamHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Create request to chatbot service
-	chatReq, err := http.NewRequest("POST", chatbotURL, bytes.NewBuffer(reqBody))
+	// No fixed timeout here: the request should live exactly as long as the client stays
+	// connected. r.Context() is canceled by net/http as soon as the client disconnects, which
+	// aborts chatReq (and the in-flight read from resp.Body below) right along with it.
+	chatReq, err := http.NewRequestWithContext(r.Context(), http.MethodPost, chatbotURL, bytes.NewBuffer(reqBody))
 	if err != nil {
 		renderHTTPError(log, r, w, errors.Wrap(err, "failed to create request"), http.StatusInternalServerError)
 		return
 	}
 	chatReq.Header.Set("Content-Type", "application/json")
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		chatReq.Header.Set("Last-Event-ID", lastEventID)
+	}
 
-	// Forward the request
-	client := &http.Client{Timeout: 60 * time.Second}
-	resp, err := client.Do(chatReq)
+	resp, err := fe.httpClient.Do(chatReq)
 	if err != nil {
+		if r.Context().Err() != nil {
+			return // client disconnected before the chatbot service responded
+		}
 		renderHTTPError(log, r, w, errors.Wrap(err, "failed to contact chatbot service"), http.StatusInternalServerError)
 		return
 	}
 	defer resp.Body.Close()
 
-	// Create a flusher first before setting headers
-	// flusher, ok := w.(http.Flusher)
-	// if !ok {
-	// 	renderHTTPError(log, r, w, errors.New("streaming unsupported"), http.StatusInternalServerError)
-	// 	return
-	// }
-
-	// Set SSE headers
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("X-Accel-Buffering", "no")
 
-	// Stream the response
-	buffer := make([]byte, 4096)
-	for {
-		n, err := resp.Body.Read(buffer)
-		if n > 0 {
-			if _, writeErr := w.Write(buffer[:n]); writeErr != nil {
-				log.WithError(writeErr).Error("failed to write streaming response")
+	sw, err := sse.NewWriter(w)
+	if err != nil {
+		renderHTTPError(log, r, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	// Decode frame-by-frame on a separate goroutine so a slow/idle upstream never blocks the
+	// flush/keepalive ticker below.
+	frames := make(chan sse.Event)
+	decodeErr := make(chan error, 1)
+	go func() {
+		defer close(frames)
+		// decodeErr is always closed on the way out, whether this goroutine exits because the
+		// decoder finished (with or without an error) or because the client disconnected
+		// mid-send below: otherwise, if frames and ctx.Done() become ready at the same instant on
+		// disconnect, the outer loop's plain <-decodeErr receive (taken when frames closes) could
+		// block forever on the path that returns without ever sending to decodeErr.
+		defer close(decodeErr)
+		dec := sse.NewDecoder(resp.Body)
+		for {
+			event, ok := dec.Next()
+			if !ok {
+				decodeErr <- dec.Err()
 				return
 			}
-			// If flusher is available, flush it. This is a best-effort approach.
-			if f, ok := w.(http.Flusher); ok {
-				f.Flush()
+			select {
+			case frames <- event:
+			case <-r.Context().Done():
+				return
 			}
 		}
-		if err != nil {
-			if err != io.EOF {
-				log.WithError(err).Error("error reading streaming response")
+	}()
+
+	flushTicker := time.NewTicker(sseFlushInterval)
+	defer flushTicker.Stop()
+	keepalive := time.NewTicker(sseKeepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case event, ok := <-frames:
+			if !ok {
+				_ = sw.Flush()
+				if err := <-decodeErr; err != nil {
+					log.WithError(err).Error("error reading streaming response")
+				}
+				return
+			}
+			if err := sw.WriteEvent(event); err != nil {
+				log.WithError(err).Error("failed to write streaming response")
+				return
 			}
-			break
+		case <-flushTicker.C:
+			if err := sw.Flush(); err != nil {
+				log.WithError(err).Error("failed to flush streaming response")
+				return
+			}
+		case <-keepalive.C:
+			if err := sw.WriteComment("keepalive"); err != nil {
+				return
+			}
+			if err := sw.Flush(); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
 		}
 	}
 }
@@ -649,7 +772,7 @@ func (fe *frontendServer) chatBotHandler(w http.ResponseWriter, r *http.Request)
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := fe.httpClientWithTimeout(30 * time.Second)
 	res, err := client.Do(httpReq)
 	if err != nil {
 		renderHTTPError(log, r, w, errors.Wrap(err, "failed to send request to chatbot service"), http.StatusInternalServerError)
@@ -687,8 +810,28 @@ func (fe *frontendServer) chatBotHandler(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// tryOnHandler proxies a try-on request to fe.tryOnSvcAddr. The human ("base_image") photo can
+// arrive two ways: the legacy single-shot multipart upload in the request body, or a completed
+// TUS resumable upload (see the tus package) referenced by the X-Upload-Id header — the latter
+// lets large/flaky mobile uploads survive a dropped connection instead of re-sending from byte
+// zero.
 func (fe *frontendServer) tryOnHandler(w http.ResponseWriter, r *http.Request) {
 	log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
+
+	if fe.tryOnSemaphore != nil {
+		release, ok := fe.tryOnSemaphore.TryAcquire(sessionID(r))
+		if !ok {
+			renderHTTPError(log, r, w, errors.New("too many try-on requests already in flight for this session"), http.StatusTooManyRequests)
+			return
+		}
+		defer release()
+	}
+
+	if uploadID := r.Header.Get("X-Upload-Id"); uploadID != "" {
+		fe.tryOnFromUpload(w, r, uploadID)
+		return
+	}
+
 	if err := r.ParseMultipartForm(20 << 20); err != nil { // 20MB
 		renderHTTPError(log, r, w, errors.Wrap(err, "invalid form"), http.StatusBadRequest)
 		return
@@ -701,68 +844,116 @@ func (fe *frontendServer) tryOnHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Retrieve product to resolve product image path
-	p, err := fe.getProduct(r.Context(), productID)
+	// Read uploaded human image
+	hf, header, err := r.FormFile("base_image")
 	if err != nil {
-		renderHTTPError(log, r, w, errors.Wrap(err, "could not retrieve product"), http.StatusInternalServerError)
+		renderHTTPError(log, r, w, errors.Wrap(err, "missing base_image file"), http.StatusBadRequest)
 		return
 	}
+	defer hf.Close()
 
-	// Open product image from local static directory
-	productPath := "." + p.GetPicture()
-	pf, err := os.Open(productPath)
-	if err != nil {
-		renderHTTPError(log, r, w, errors.Wrapf(err, "failed to open product image: %s", productPath), http.StatusInternalServerError)
+	fe.doTryOn(w, r, productID, category, header.Filename, hf)
+}
+
+// tryOnFromUpload handles the X-Upload-Id path: product_id/category come from the query string
+// (there's no multipart form body to carry them), and the human image is streamed straight out of
+// fe.tusStore instead of being re-uploaded.
+func (fe *frontendServer) tryOnFromUpload(w http.ResponseWriter, r *http.Request, uploadID string) {
+	log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
+
+	productID := r.URL.Query().Get("product_id")
+	category := r.URL.Query().Get("category")
+	if productID == "" {
+		renderHTTPError(log, r, w, errors.New("missing product_id"), http.StatusBadRequest)
 		return
 	}
-	defer pf.Close()
 
-	// Read uploaded human image
-	hf, header, err := r.FormFile("base_image")
+	upload, err := fe.tusStore.Get(r.Context(), uploadID)
 	if err != nil {
-		renderHTTPError(log, r, w, errors.Wrap(err, "missing base_image file"), http.StatusBadRequest)
+		renderHTTPError(log, r, w, errors.Wrap(err, "unknown upload"), http.StatusBadRequest)
+		return
+	}
+	if !upload.Complete() {
+		renderHTTPError(log, r, w, errors.New("upload is not yet complete"), http.StatusBadRequest)
 		return
 	}
-	defer hf.Close()
 
-	// Build multipart payload to try-on service
-	var body bytes.Buffer
-	mw := multipart.NewWriter(&body)
-	// product image part
-	pw, err := mw.CreateFormFile("product_image", "product"+filepathExtSafe(productPath))
+	hf, err := fe.tusStore.Reader(r.Context(), uploadID)
 	if err != nil {
-		renderHTTPError(log, r, w, errors.Wrap(err, "failed to create part"), http.StatusInternalServerError)
+		renderHTTPError(log, r, w, errors.Wrap(err, "failed to open uploaded image"), http.StatusInternalServerError)
 		return
 	}
-	if _, err := io.Copy(pw, pf); err != nil {
-		renderHTTPError(log, r, w, errors.Wrap(err, "failed to copy product image"), http.StatusInternalServerError)
-		return
+	defer hf.Close()
+
+	filename := upload.Metadata["filename"]
+	if filename == "" {
+		filename = "base_image"
 	}
-	// human image part
-	hw, err := mw.CreateFormFile("base_image", header.Filename)
+	fe.doTryOn(w, r, productID, category, filename, hf)
+}
+
+// doTryOn resolves productID's image, streams both it and baseImage into a multipart request to
+// the try-on service without buffering either in memory, and proxies the response back verbatim.
+func (fe *frontendServer) doTryOn(w http.ResponseWriter, r *http.Request, productID, category, baseImageFilename string, baseImage io.Reader) {
+	log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
+
+	p, err := fe.getProduct(r.Context(), productID)
 	if err != nil {
-		renderHTTPError(log, r, w, errors.Wrap(err, "failed to create part"), http.StatusInternalServerError)
+		renderHTTPError(log, r, w, errors.Wrap(err, "could not retrieve product"), http.StatusInternalServerError)
 		return
 	}
-	if _, err := io.Copy(hw, hf); err != nil {
-		renderHTTPError(log, r, w, errors.Wrap(err, "failed to copy base image"), http.StatusInternalServerError)
+
+	productPath := "." + p.GetPicture()
+	pf, err := os.Open(productPath)
+	if err != nil {
+		renderHTTPError(log, r, w, errors.Wrapf(err, "failed to open product image: %s", productPath), http.StatusInternalServerError)
 		return
 	}
+	defer pf.Close()
 
-	// category part
-	mw.WriteField("category", category)
-	mw.Close()
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		err := func() error {
+			productPart, err := mw.CreateFormFile("product_image", "product"+filepathExtSafe(productPath))
+			if err != nil {
+				return errors.Wrap(err, "failed to create product_image part")
+			}
+			if _, err := io.Copy(productPart, pf); err != nil {
+				return errors.Wrap(err, "failed to stream product image")
+			}
+
+			basePart, err := mw.CreateFormFile("base_image", baseImageFilename)
+			if err != nil {
+				return errors.Wrap(err, "failed to create base_image part")
+			}
+			if _, err := io.Copy(basePart, baseImage); err != nil {
+				return errors.Wrap(err, "failed to stream base image")
+			}
+
+			if err := mw.WriteField("category", category); err != nil {
+				return errors.Wrap(err, "failed to write category field")
+			}
+			return mw.Close()
+		}()
+		pw.CloseWithError(err)
+	}()
 
 	url := "http://" + fe.tryOnSvcAddr + "/tryon"
-	req, err := http.NewRequest(http.MethodPost, url, &body)
-	if err != nil {
-		renderHTTPError(log, r, w, errors.Wrap(err, "failed to create request"), http.StatusInternalServerError)
-		return
+	build := func(attemptCtx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(attemptCtx, http.MethodPost, url, pr)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		return req, nil
 	}
-	req.Header.Set("Content-Type", mw.FormDataContentType())
-	res, err := http.DefaultClient.Do(req)
+
+	// routeTryOn is registered with MaxAttempts 1: pr streams product/base images exactly once, so
+	// there's nothing to replay on a retried attempt.
+	res, err := fe.tryOnClient.Do(r.Context(), routeTryOn, build)
 	if err != nil {
-		renderHTTPError(log, r, w, errors.Wrap(err, "failed to call try-on service"), http.StatusBadGateway)
+		renderUpstreamError(log, r, w, errors.Wrap(err, "failed to call try-on service"), http.StatusBadGateway)
 		return
 	}
 	defer res.Body.Close()
@@ -812,17 +1003,91 @@ func (fe *frontendServer) setCurrencyHandler(w http.ResponseWriter, r *http.Requ
 	w.WriteHeader(http.StatusFound)
 }
 
-// chooseAd queries for advertisements available and randomly chooses one, if
-// available. It ignores the error retrieving the ad since it is not critical.
+// chooseAd queries for advertisements available and picks one via the ad bandit (see the bandit
+// package), which learns which ad converts best per category context instead of picking
+// uniformly at random. It ignores the error retrieving the ad since it is not critical.
 func (fe *frontendServer) chooseAd(ctx context.Context, ctxKeys []string, log logrus.FieldLogger) *pb.Ad {
 	ads, err := fe.getAd(ctx, ctxKeys)
 	if err != nil {
 		log.WithField("error", err).Warn("failed to retrieve ads")
 		return nil
 	}
+	if len(ads) == 1 {
+		return ads[0]
+	}
+
+	byArmID := make(map[string]*pb.Ad, len(ads))
+	armIDs := make([]string, 0, len(ads))
+	for _, ad := range ads {
+		armIDs = append(armIDs, ad.GetRedirectUrl())
+		byArmID[ad.GetRedirectUrl()] = ad
+	}
+
+	armID, ok := fe.adBandit.SelectArm(ctx, adBanditSegment(ctxKeys), armIDs)
+	if !ok {
+		log.Warn("ad bandit store unreachable, falling back to uniform random")
+	}
+	if ad, found := byArmID[armID]; found {
+		return ad
+	}
 	return ads[rand.Intn(len(ads))]
 }
 
+// adBanditSegment derives a stable bandit segment key from the category context an ad was
+// requested with, so arm posteriors are tracked per category rather than globally.
+func adBanditSegment(ctxKeys []string) string {
+	if len(ctxKeys) == 0 {
+		return "default"
+	}
+	sorted := append([]string(nil), ctxKeys...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// adRewardHandler records a reward (click/add-to-cart/purchase) against the ad bandit arm that
+// served an impression, so future chooseAd calls favor ads that actually convert.
+func (fe *frontendServer) adRewardHandler(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
+
+	var req struct {
+		Segment     string  `json:"segment"`
+		RedirectURL string  `json:"redirect_url"`
+		Reward      float64 `json:"reward"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		renderHTTPError(log, r, w, errors.Wrap(err, "invalid reward payload"), http.StatusBadRequest)
+		return
+	}
+	if req.RedirectURL == "" {
+		renderHTTPError(log, r, w, errors.New("redirect_url is required"), http.StatusBadRequest)
+		return
+	}
+	if req.Segment == "" {
+		req.Segment = "default"
+	}
+
+	if err := fe.adBandit.RecordReward(r.Context(), req.Segment, req.RedirectURL, req.Reward); err != nil {
+		renderHTTPError(log, r, w, errors.Wrap(err, "failed to record ad reward"), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// debugBanditHandler returns every tracked (segment, arm) posterior for observability.
+func (fe *frontendServer) debugBanditHandler(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
+
+	snapshot, err := fe.adBandit.Snapshot(r.Context())
+	if err != nil {
+		renderHTTPError(log, r, w, errors.Wrap(err, "failed to snapshot ad bandit"), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(snapshot); err != nil {
+		log.WithError(err).Warn("failed to encode bandit snapshot")
+	}
+}
+
 func renderHTTPError(log logrus.FieldLogger, r *http.Request, w http.ResponseWriter, err error, code int) {
 	log.WithField("error", err).Error("request error")
 	errMsg := fmt.Sprintf("%+v", err)
@@ -854,6 +1119,19 @@ func renderHTTPError(log logrus.FieldLogger, r *http.Request, w http.ResponseWri
 	}
 }
 
+// renderUpstreamError renders an error returned by fe.tryOnClient.Do/fe.videoGenClient.Do. If err
+// is an *httpx.BreakerOpenError (the route's circuit breaker has tripped), it's surfaced as 503
+// with a Retry-After header instead of fallbackCode, so a client that's hammering a down upstream
+// backs off instead of retrying immediately.
+func renderUpstreamError(log logrus.FieldLogger, r *http.Request, w http.ResponseWriter, err error, fallbackCode int) {
+	if boe, ok := errors.Cause(err).(*httpx.BreakerOpenError); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(boe.RetryAfter.Seconds())))
+		renderHTTPError(log, r, w, err, http.StatusServiceUnavailable)
+		return
+	}
+	renderHTTPError(log, r, w, err, fallbackCode)
+}
+
 // userFacingMessage maps common error codes to simpler, friendly messages displayed in the FE.
 func userFacingMessage(code int, fallback string) string {
 	switch code {
@@ -885,6 +1163,7 @@ func injectCommonTemplateData(r *http.Request, payload map[string]interface{}) m
 		"currentYear":       time.Now().Year(),
 		"baseUrl":           baseUrl,
 		"IsSignedIn":        isUserSignedIn(r),
+		"csrf_token":        csrfToken(sessionID(r)),
 	}
 
 	for k, v := range payload {
@@ -910,6 +1189,30 @@ func sessionID(r *http.Request) string {
 	return ""
 }
 
+// rateLimited wraps h with fe.rateLimiter's middleware for the given route class, keyed by
+// session cookie and client IP, or returns h unwrapped if no rate limiter is configured.
+func (fe *frontendServer) rateLimited(class middleware.RouteClass, h http.HandlerFunc) http.HandlerFunc {
+	if fe.rateLimiter == nil {
+		return h
+	}
+	return fe.rateLimiter.Middleware(class, sessionID, clientIP)(h).ServeHTTP
+}
+
+// clientIP returns the first address in X-Forwarded-For (set by the load balancer/ingress in
+// front of the frontend), falling back to the direct connection's address.
+func clientIP(r *http.Request) string {
+	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+		if parts := strings.Split(fwd, ","); len(parts) > 0 {
+			return strings.TrimSpace(parts[0])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 func cartIDs(c []*pb.CartItem) []string {
 	out := make([]string, len(c))
 	for i, v := range c {
@@ -994,31 +1297,58 @@ func (fe *frontendServer) generateAdsHandler(w http.ResponseWriter, r *http.Requ
 	}
 }
 
+// searchProductsForAdsHandler fans the query out across the product catalog and any configured
+// external providers (see fe.productSearch/the productsearch package) and streams each source's
+// results as an SSE "partial" frame so the ad-generation UI can render progressively, instead of
+// waiting on a single upstream call. The final "done" frame carries the deduplicated, ranked
+// merge. Sources that errored or timed out are reported in a trailing X-Partial-Sources header
+// rather than failing the request.
 func (fe *frontendServer) searchProductsForAdsHandler(w http.ResponseWriter, r *http.Request) {
 	log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
 	query := r.URL.Query().Get("q")
 
-	// Call video generation service to search products
-	searchURL := fmt.Sprintf("http://%s/products/search?q=%s", fe.videoGenerationSvcAddr, query)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	// X-Partial-Sources isn't known until every source has resolved or timed out, so it's sent as
+	// a trailer instead of a leading header.
+	w.Header().Set("Trailer", "X-Partial-Sources")
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(searchURL)
+	sw, err := sse.NewWriter(w)
 	if err != nil {
-		log.WithError(err).Error("failed to search products for ads")
-		http.Error(w, "Failed to search products", http.StatusInternalServerError)
+		renderUpstreamError(log, r, w, errors.Wrap(err, "failed to start product search stream"), http.StatusInternalServerError)
 		return
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	result := fe.productSearch.Search(r.Context(), query, func(partial productsearch.SourceResult) {
+		if partial.Err != nil {
+			log.WithError(partial.Err).WithField("source", partial.Source).Warn("product search source failed")
+			return
+		}
+		payload, err := json.Marshal(partial.Items)
+		if err != nil {
+			log.WithError(err).Error("failed to encode product search partial frame")
+			return
+		}
+		if err := sw.WriteEvent(sse.Event{Event: "partial", Data: string(payload)}); err != nil {
+			log.WithError(err).Error("failed to write product search partial frame")
+			return
+		}
+		_ = sw.Flush()
+	})
+
+	payload, err := json.Marshal(result.Items)
 	if err != nil {
-		log.WithError(err).Error("failed to read search response")
-		http.Error(w, "Failed to read response", http.StatusInternalServerError)
-		return
+		log.WithError(err).Error("failed to encode product search result")
+		payload = []byte("[]")
 	}
+	if err := sw.WriteEvent(sse.Event{Event: "done", Data: string(payload)}); err != nil {
+		log.WithError(err).Error("failed to write product search done frame")
+	}
+	_ = sw.Flush()
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(body)
+	w.Header().Set("X-Partial-Sources", strings.Join(result.Failed, ","))
 }
 
 func (fe *frontendServer) generateVideoHandler(w http.ResponseWriter, r *http.Request) {
@@ -1038,11 +1368,16 @@ func (fe *frontendServer) generateVideoHandler(w http.ResponseWriter, r *http.Re
 	generateURL := fmt.Sprintf("http://%s/generate-ad", fe.videoGenerationSvcAddr)
 	reqBody, _ := json.Marshal(map[string]string{"product_id": req.ProductID})
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Post(generateURL, "application/json", strings.NewReader(string(reqBody)))
+	resp, err := fe.videoGenClient.Do(r.Context(), routeVideoGenGenerate, func(attemptCtx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(attemptCtx, http.MethodPost, generateURL, bytes.NewReader(reqBody))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
 	if err != nil {
-		log.WithError(err).Error("failed to start video generation")
-		http.Error(w, "Failed to start video generation", http.StatusInternalServerError)
+		renderUpstreamError(log, r, w, errors.Wrap(err, "failed to start video generation"), http.StatusInternalServerError)
 		return
 	}
 	defer resp.Body.Close()
@@ -1065,11 +1400,11 @@ func (fe *frontendServer) videoStatusHandler(w http.ResponseWriter, r *http.Requ
 	// Call video generation service to check status
 	statusURL := fmt.Sprintf("http://%s/video-status/%s", fe.videoGenerationSvcAddr, jobID)
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(statusURL)
+	resp, err := fe.videoGenClient.Do(r.Context(), routeVideoGenStatus, func(attemptCtx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(attemptCtx, http.MethodGet, statusURL, nil)
+	})
 	if err != nil {
-		log.WithError(err).Error("failed to check video status")
-		http.Error(w, "Failed to check status", http.StatusInternalServerError)
+		renderUpstreamError(log, r, w, errors.Wrap(err, "failed to check video status"), http.StatusInternalServerError)
 		return
 	}
 	defer resp.Body.Close()
@@ -1081,10 +1416,140 @@ func (fe *frontendServer) videoStatusHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	var status map[string]interface{}
+	if err := json.Unmarshal(body, &status); err != nil {
+		log.WithError(err).Error("failed to decode status response")
+		http.Error(w, "Failed to read response", http.StatusInternalServerError)
+		return
+	}
+
+	// A moderation decision overrides the upstream's opaque status: the storefront needs to show
+	// "pending review" vs "approved" vs "rejected: reason" regardless of what the video-generation
+	// service itself thinks the job's state is.
+	if item, err := fe.moderation.Get(r.Context(), jobID); err != nil {
+		log.WithError(err).Warn("failed to look up moderation status")
+	} else if item != nil {
+		status["moderation_status"] = item.Status
+		if item.Reason != "" {
+			status["moderation_reason"] = item.Reason
+		}
+	}
+
+	body, err = json.Marshal(status)
+	if err != nil {
+		log.WithError(err).Error("failed to re-encode status response")
+		http.Error(w, "Failed to read response", http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(body)
 }
 
+// videoProgress is the JSON payload carried by a video-events "progress"/"validated" frame.
+type videoProgress struct {
+	Percent  int    `json:"percent"`
+	Stage    string `json:"stage"`
+	AssetURL string `json:"asset_url,omitempty"`
+}
+
+// openVideoEventsUpstream dials the video-generation service's own progress stream for jobID. It
+// implements videohub.Open.
+func (fe *frontendServer) openVideoEventsUpstream(ctx context.Context, jobID string) (io.ReadCloser, error) {
+	url := fmt.Sprintf("http://%s/video-events/%s", fe.videoGenerationSvcAddr, jobID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create video-events request")
+	}
+	resp, err := fe.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to contact video-generation service")
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.Errorf("video-events upstream returned status %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// videoEventsHandler streams a video-generation job's progress as SSE, so the browser doesn't
+// need to poll videoStatusHandler. Every tab watching the same job_id shares one upstream
+// subscription via fe.videoHub; a terminal "done" frame also synthesizes a Notification so the
+// async video-ad UX matches try-on's.
+func (fe *frontendServer) videoEventsHandler(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
+	jobID := mux.Vars(r)["job_id"]
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	sw, err := sse.NewWriter(w)
+	if err != nil {
+		renderHTTPError(log, r, w, err, http.StatusInternalServerError)
+		return
+	}
+
+	frames, unsubscribe := fe.videoHub.Subscribe(jobID)
+	defer unsubscribe()
+
+	flushTicker := time.NewTicker(sseFlushInterval)
+	defer flushTicker.Stop()
+	keepalive := time.NewTicker(sseKeepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case event, ok := <-frames:
+			if !ok {
+				_ = sw.Flush()
+				return
+			}
+			if err := sw.WriteEvent(event); err != nil {
+				log.WithError(err).Error("failed to write video-events frame")
+				return
+			}
+			if event.Event == "done" {
+				fe.notifyVideoDone(r, jobID, event)
+			}
+		case <-flushTicker.C:
+			if err := sw.Flush(); err != nil {
+				log.WithError(err).Error("failed to flush video-events stream")
+				return
+			}
+		case <-keepalive.C:
+			if err := sw.WriteComment("keepalive"); err != nil {
+				return
+			}
+			if err := sw.Flush(); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// notifyVideoDone synthesizes a Notification from a terminal video-events frame, unifying the
+// try-on and ad-generation async UX (both end with a Notification in fe.notifications). It also
+// enqueues the finished job for admin moderation: a video isn't reviewable until it exists.
+func (fe *frontendServer) notifyVideoDone(r *http.Request, jobID string, event sse.Event) {
+	var progress videoProgress
+	_ = json.Unmarshal([]byte(event.Data), &progress)
+
+	message := fmt.Sprintf("Your video ad for job %s is ready", jobID)
+	if progress.AssetURL != "" {
+		message = fmt.Sprintf("Your video ad is ready: %s", progress.AssetURL)
+	}
+	fe.notifications.AddNotification(r.Context(), sessionID(r), sessionID(r), message)
+
+	if err := fe.moderation.Submit(r.Context(), jobID); err != nil {
+		log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
+		log.WithError(err).WithField("job_id", jobID).Warn("failed to enqueue job for moderation")
+	}
+}
+
 func (fe *frontendServer) validateVideoHandler(w http.ResponseWriter, r *http.Request) {
 	log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
 
@@ -1103,11 +1568,16 @@ func (fe *frontendServer) validateVideoHandler(w http.ResponseWriter, r *http.Re
 	validateURL := fmt.Sprintf("http://%s/validate-video", fe.videoGenerationSvcAddr)
 	reqBody, _ := json.Marshal(req)
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Post(validateURL, "application/json", strings.NewReader(string(reqBody)))
+	resp, err := fe.videoGenClient.Do(r.Context(), routeVideoGenValidate, func(attemptCtx context.Context) (*http.Request, error) {
+		httpReq, err := http.NewRequestWithContext(attemptCtx, http.MethodPost, validateURL, bytes.NewReader(reqBody))
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		return httpReq, nil
+	})
 	if err != nil {
-		log.WithError(err).Error("failed to validate video")
-		http.Error(w, "Failed to validate video", http.StatusInternalServerError)
+		renderUpstreamError(log, r, w, errors.Wrap(err, "failed to validate video"), http.StatusInternalServerError)
 		return
 	}
 	defer resp.Body.Close()
@@ -1123,37 +1593,344 @@ func (fe *frontendServer) validateVideoHandler(w http.ResponseWriter, r *http.Re
 	w.Write(body)
 }
 
+// adminModerationListHandler serves one page of the moderation queue, filterable by status,
+// reviewer, and submission-date range via query params.
+func (fe *frontendServer) adminModerationListHandler(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
+
+	filter := moderation.ListFilter{
+		Status:   moderation.Status(r.URL.Query().Get("status")),
+		Reviewer: r.URL.Query().Get("reviewer"),
+	}
+	if v := r.URL.Query().Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			renderHTTPError(log, r, w, errors.Wrap(err, "invalid since"), http.StatusBadRequest)
+			return
+		}
+		filter.Since = since
+	}
+	if v := r.URL.Query().Get("until"); v != "" {
+		until, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			renderHTTPError(log, r, w, errors.Wrap(err, "invalid until"), http.StatusBadRequest)
+			return
+		}
+		filter.Until = until
+	}
+	if v := r.URL.Query().Get("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil {
+			renderHTTPError(log, r, w, errors.Wrap(err, "invalid page"), http.StatusBadRequest)
+			return
+		}
+		filter.Page = page
+	}
+	if v := r.URL.Query().Get("per_page"); v != "" {
+		perPage, err := strconv.Atoi(v)
+		if err != nil {
+			renderHTTPError(log, r, w, errors.Wrap(err, "invalid per_page"), http.StatusBadRequest)
+			return
+		}
+		filter.PerPage = perPage
+	}
+
+	result, err := fe.moderation.List(r.Context(), filter)
+	if err != nil {
+		renderHTTPError(log, r, w, errors.Wrap(err, "failed to list moderation queue"), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// adminModerationDecisionHandler records a reviewer's approve/reject/request-changes decision for
+// a job, appending it to the immutable audit log and updating the job's queue entry to match. The
+// reviewer identity comes from the BasicAuth credentials that gated this route; the content hash
+// is computed from the video on disk at decision time, so the audit log ties a decision to the
+// exact bytes the reviewer saw.
+func (fe *frontendServer) adminModerationDecisionHandler(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
+	jobID := mux.Vars(r)["job_id"]
+
+	var req struct {
+		Outcome      moderation.Outcome `json:"outcome"`
+		Reason       string             `json:"reason"`
+		PolicyLabels []string           `json:"policy_labels"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		renderHTTPError(log, r, w, errors.Wrap(err, "failed to decode decision"), http.StatusBadRequest)
+		return
+	}
+	if !req.Outcome.Valid() {
+		renderHTTPError(log, r, w, errors.Errorf("invalid outcome %q", req.Outcome), http.StatusBadRequest)
+		return
+	}
+
+	reviewer, _, _ := r.BasicAuth()
+
+	contentHash, err := fe.moderationContentHash(jobID)
+	if err != nil {
+		log.WithError(err).WithField("job_id", jobID).Warn("failed to hash video for moderation decision")
+	}
+
+	decision := moderation.Decision{
+		JobID:        jobID,
+		Outcome:      req.Outcome,
+		Reason:       req.Reason,
+		PolicyLabels: req.PolicyLabels,
+		Reviewer:     reviewer,
+		ClientIP:     clientIP(r),
+		ContentHash:  contentHash,
+		OccurredAt:   time.Now(),
+	}
+	if err := fe.moderation.RecordDecision(r.Context(), decision); err != nil {
+		renderHTTPError(log, r, w, errors.Wrap(err, "failed to record moderation decision"), http.StatusInternalServerError)
+		return
+	}
+
+	item, err := fe.moderation.Get(r.Context(), jobID)
+	if err != nil {
+		renderHTTPError(log, r, w, errors.Wrap(err, "failed to reload moderation item"), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// moderationContentHash returns the sha256 of jobID's source video as currently cached on disk, or
+// "" if it isn't (yet) cached.
+func (fe *frontendServer) moderationContentHash(jobID string) (string, error) {
+	f, err := os.Open(fe.videoStore.SourcePath(jobID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", errors.Wrap(err, "failed to open video for hashing")
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", errors.Wrap(err, "failed to hash video")
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// serveVideoHandler serves a generated ad video, transcoding it to an HLS rendition ladder on
+// first access (see the video package). It content-negotiates: a client that Accepts
+// application/vnd.apple.mpegurl gets the HLS master playlist, everything else gets the original
+// progressive MP4 served via http.ServeContent, which handles Range/ETag/conditional GET.
 func (fe *frontendServer) serveVideoHandler(w http.ResponseWriter, r *http.Request) {
 	log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
 	filename := mux.Vars(r)["filename"]
 
-	// Proxy video request to video generation service
+	if err := fe.videoStore.Ensure(r.Context(), filename, func(ctx context.Context) (io.ReadCloser, error) {
+		return fe.fetchSourceVideo(ctx, filename)
+	}); err != nil {
+		log.WithError(err).Error("failed to prepare video renditions")
+		http.Error(w, "Failed to prepare video", http.StatusInternalServerError)
+		return
+	}
+
+	if acceptsHLS(r) {
+		w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+		http.ServeFile(w, r, fe.videoStore.MasterPlaylistPath(filename))
+		return
+	}
+
+	f, err := os.Open(fe.videoStore.SourcePath(filename))
+	if err != nil {
+		log.WithError(err).Error("failed to open cached source video")
+		http.Error(w, "Failed to open video", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		log.WithError(err).Error("failed to stat cached source video")
+		http.Error(w, "Failed to open video", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "video/mp4")
+	w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, stat.ModTime().UnixNano(), stat.Size()))
+	http.ServeContent(w, r, filename, stat.ModTime(), f)
+}
+
+// acceptsHLS reports whether the client asked for the HLS master playlist over a progressive MP4.
+func acceptsHLS(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/vnd.apple.mpegurl") ||
+		r.URL.Query().Get("format") == "hls"
+}
+
+// fetchSourceVideo retrieves jobID/filename's original MP4 from the video-generation service, for
+// the video package's Store to cache and transcode. It implements video.FetchSource.
+func (fe *frontendServer) fetchSourceVideo(ctx context.Context, filename string) (io.ReadCloser, error) {
 	videoURL := fmt.Sprintf("http://%s/video/%s", fe.videoGenerationSvcAddr, filename)
+	resp, err := fe.videoGenClient.Do(ctx, routeVideoGenSource, func(attemptCtx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(attemptCtx, http.MethodGet, videoURL, nil)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch source video")
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.Errorf("video-generation service returned status %d for %s", resp.StatusCode, filename)
+	}
+	return resp.Body, nil
+}
+
+// notificationHeartbeatInterval is how often notificationsStreamHandler sends a keepalive
+// comment frame, so an idle connection doesn't get closed by a proxy/load balancer that times
+// out silent connections.
+const notificationHeartbeatInterval = 30 * time.Second
+
+// notificationsStreamHandler pushes new notifications to the current session over SSE instead
+// of requiring the client to poll getNotificationsHandler. A reconnect carrying a Last-Event-ID
+// header replays whatever notifications arrived after that ID (see unreadSince) before the
+// stream switches to live push via fe.notifications.Subscribe.
+func (fe *frontendServer) notificationsStreamHandler(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(ctxKeyLog{}).(logrus.FieldLogger)
+	sessID := r.Context().Value(ctxKeySessionID{}).(string)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Get(videoURL)
+	sw, err := sse.NewWriter(w)
 	if err != nil {
-		log.WithError(err).Error("failed to fetch video")
-		http.Error(w, "Failed to fetch video", http.StatusInternalServerError)
+		renderHTTPError(log, r, w, err, http.StatusInternalServerError)
 		return
 	}
-	defer resp.Body.Close()
 
-	// Copy headers
-	for k, v := range resp.Header {
-		w.Header()[k] = v
+	replay := unreadSince(fe.notifications.GetNotifications(r.Context(), sessID), r.Header.Get("Last-Event-ID"))
+	for _, n := range replay {
+		if err := writeNotificationEvent(sw, n); err != nil {
+			log.WithError(err).Error("failed to write replayed notification frame")
+			return
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return
+	}
+
+	notifications, unsubscribe := fe.notifications.Subscribe(sessID)
+	defer unsubscribe()
+
+	heartbeat := time.NewTicker(notificationHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case n, ok := <-notifications:
+			if !ok {
+				return
+			}
+			if err := writeNotificationEvent(sw, n); err != nil {
+				log.WithError(err).Error("failed to write notification frame")
+				return
+			}
+			if err := sw.Flush(); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := sw.WriteComment("heartbeat"); err != nil {
+				return
+			}
+			if err := sw.Flush(); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeNotificationEvent(sw *sse.Writer, n *Notification) error {
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	return sw.WriteEvent(sse.Event{ID: n.ID, Event: "notification", Data: string(payload)})
+}
+
+// unreadSince returns the notifications in all that arrived after lastEventID (the client's
+// Last-Event-ID on reconnect). An empty lastEventID means a fresh connection, which only wants
+// to hear about what happens next. A lastEventID that's aged out of all (the session's history
+// predates it) replays everything still available rather than silently dropping notifications.
+func unreadSince(all []*Notification, lastEventID string) []*Notification {
+	if lastEventID == "" {
+		return nil
+	}
+	for i, n := range all {
+		if n.ID == lastEventID {
+			return all[i+1:]
+		}
+	}
+	return all
+}
+
+// healthzHandler is a pure liveness probe: it reports ok as soon as the process is up, regardless
+// of whether any downstream dependency is reachable, so Kubernetes doesn't restart a pod that's
+// merely waiting on a backend to come up (see readyHandler for that check).
+func (fe *frontendServer) healthzHandler(w http.ResponseWriter, _ *http.Request) {
+	fmt.Fprint(w, "ok")
+}
+
+// readyHandler is the readiness probe: it reports 503 while the process is shutting down (see
+// main()'s svc.shuttingDown, so the load balancer stops sending new traffic here before in-flight
+// requests finish draining) or while any dialBackend connection isn't connectivity.Ready yet, and
+// pings the notification store's Redis connection when NOTIFICATION_STORE=redis.
+func (fe *frontendServer) readyHandler(w http.ResponseWriter, r *http.Request) {
+	if fe.shuttingDown.Load() {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
+	conns := map[string]*grpc.ClientConn{
+		"currency":        fe.currencySvcConn,
+		"product_catalog": fe.productCatalogSvcConn,
+		"cart":            fe.cartSvcConn,
+		"recommendation":  fe.recommendationSvcConn,
+		"shipping":        fe.shippingSvcConn,
+		"checkout":        fe.checkoutSvcConn,
+		"ad":              fe.adSvcConn,
+	}
+	for name, conn := range conns {
+		if conn == nil {
+			http.Error(w, name+" backend not dialed", http.StatusServiceUnavailable)
+			return
+		}
+		state := conn.GetState()
+		if state == connectivity.Idle {
+			conn.Connect() // nudge a lazily-idle conn into CONNECTING instead of waiting for a real RPC
+		}
+		if state != connectivity.Ready {
+			http.Error(w, name+" backend not ready", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	if rs, ok := fe.notifications.(*redisStore); ok {
+		if err := rs.client.Ping(r.Context()).Err(); err != nil {
+			http.Error(w, "redis unavailable", http.StatusServiceUnavailable)
+			return
+		}
 	}
-	w.WriteHeader(resp.StatusCode)
 
-	// Copy body
-	io.Copy(w, resp.Body)
+	fmt.Fprint(w, "ready")
 }
 
 // getNotificationsHandler returns all notifications for the current session
 func (fe *frontendServer) getNotificationsHandler(w http.ResponseWriter, r *http.Request) {
 	sessionID := r.Context().Value(ctxKeySessionID{}).(string)
 	fmt.Println("sessionID", sessionID)
-	notifications := fe.notifications.GetNotifications(sessionID)
+	notifications := fe.notifications.GetNotifications(r.Context(), sessionID)
 	fmt.Println("notifications", notifications)
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(notifications); err != nil {
@@ -1174,7 +1951,7 @@ func (fe *frontendServer) markNotificationReadHandler(w http.ResponseWriter, r *
 		return
 	}
 
-	fe.notifications.MarkAsRead(sessionID, notificationID)
+	fe.notifications.MarkAsRead(r.Context(), sessionID, notificationID)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
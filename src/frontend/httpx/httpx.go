@@ -0,0 +1,231 @@
+// Package httpx is the plain-HTTP counterpart to the resilience package's gRPC interceptor: a
+// Client that applies a per-route timeout, exponential-backoff retry with jitter, a circuit
+// breaker, and a bounded in-flight semaphore to outbound calls, instead of each handler
+// instantiating its own ad-hoc *http.Client. frontendServer configures one Client per upstream
+// (tryOnClient, videoGenClient, ...) at startup; handlers call Client.Do with a route name that
+// selects that route's policy.
+package httpx
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/resilience"
+)
+
+// RoutePolicy configures one named route: resilience.Policy's timeout/retry/breaker settings plus
+// an HTTP-specific cap on concurrent in-flight requests.
+type RoutePolicy struct {
+	resilience.Policy
+	MaxInFlight int // zero means unbounded
+}
+
+// DefaultRoutePolicy is applied to any route that isn't explicitly Registered and has no env vars
+// set for it.
+var DefaultRoutePolicy = RoutePolicy{Policy: resilience.DefaultPolicy, MaxInFlight: 50}
+
+// RoutePolicyFromEnv builds a RoutePolicy for a route, reading <prefix>_MAX_INFLIGHT in addition
+// to the env vars resilience.PolicyFromEnv(prefix) reads, falling back to DefaultRoutePolicy for
+// anything unset.
+func RoutePolicyFromEnv(prefix string) RoutePolicy {
+	policy := DefaultRoutePolicy
+	policy.Policy = resilience.PolicyFromEnv(prefix)
+	if v, ok := resilience.IntEnv(prefix + "_MAX_INFLIGHT"); ok {
+		policy.MaxInFlight = v
+	}
+	return policy
+}
+
+// BreakerOpenError is returned by Client.Do when route's breaker is open. Callers can recover it
+// with errors.Cause(err).(*httpx.BreakerOpenError) to surface a Retry-After header.
+type BreakerOpenError struct {
+	Route      string
+	RetryAfter time.Duration
+}
+
+func (e *BreakerOpenError) Error() string {
+	return "httpx: circuit breaker open for route " + e.Route
+}
+
+// HeaderRotator returns headers to merge onto every outgoing request for a route — e.g. rotating
+// User-Agent strings or API keys across a pool. Returning nil or an empty Header is a no-op.
+type HeaderRotator func() http.Header
+
+// Client dispatches HTTP requests through named routes, each with its own timeout/retry/breaker/
+// concurrency policy.
+type Client struct {
+	transport     *http.Client
+	headerRotator HeaderRotator
+
+	mu     sync.Mutex
+	routes map[string]*route
+}
+
+type route struct {
+	name    string
+	policy  RoutePolicy
+	breaker *resilience.Breaker
+	sem     chan struct{}
+}
+
+// New builds a Client. transport is the shared *http.Client whose pooled Transport every route
+// reuses (only its Timeout is overridden per-attempt, via the request context); rotator may be nil.
+func New(transport *http.Client, rotator HeaderRotator) *Client {
+	return &Client{transport: transport, headerRotator: rotator, routes: make(map[string]*route)}
+}
+
+// Register explicitly configures name's policy; call it at startup for routes that need settings
+// other than RoutePolicyFromEnv's env-driven defaults. Routes used without a prior Register fall
+// back to RoutePolicyFromEnv(name).
+func (c *Client) Register(name string, policy RoutePolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.routes[name] = newRoute(name, policy)
+}
+
+func newRoute(name string, policy RoutePolicy) *route {
+	var sem chan struct{}
+	if policy.MaxInFlight > 0 {
+		sem = make(chan struct{}, policy.MaxInFlight)
+	}
+	return &route{
+		name:    name,
+		policy:  policy,
+		breaker: resilience.NewBreaker(policy.BreakerThreshold, policy.BreakerCooldown),
+		sem:     sem,
+	}
+}
+
+func (c *Client) routeFor(name string) *route {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rt, ok := c.routes[name]
+	if !ok {
+		rt = newRoute(name, RoutePolicyFromEnv(name))
+		c.routes[name] = rt
+	}
+	return rt
+}
+
+// Build constructs one attempt's *http.Request against attemptCtx (which carries route's
+// per-attempt timeout). Do calls it once per attempt, since a request whose body has already been
+// read can't be resent as-is.
+type Build func(attemptCtx context.Context) (*http.Request, error)
+
+// Do runs build's request through route's timeout/retry/breaker/concurrency policy. On success it
+// returns the *http.Response with its Body wrapped so that closing it also releases the per-attempt
+// timeout context and (if route is bounded) the in-flight slot — callers should Close the body
+// exactly as they would for a plain http.Client.Do result.
+func (c *Client) Do(ctx context.Context, routeName string, build Build) (*http.Response, error) {
+	rt := c.routeFor(routeName)
+
+	release := func() {}
+	if rt.sem != nil {
+		select {
+		case rt.sem <- struct{}{}:
+			release = func() { <-rt.sem }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	resp, err := c.doWithRetry(ctx, rt, build)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releaseOnCloseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+func (c *Client) doWithRetry(ctx context.Context, rt *route, build Build) (*http.Response, error) {
+	var lastErr error
+	for attempt := 0; attempt < rt.policy.MaxAttempts; attempt++ {
+		if !rt.breaker.Allow() {
+			observeBreakerState(rt.name, true)
+			return nil, &BreakerOpenError{Route: rt.name, RetryAfter: rt.policy.BreakerCooldown}
+		}
+		observeBreakerState(rt.name, false)
+
+		attemptCtx, cancel := context.WithTimeout(ctx, rt.policy.Timeout)
+		req, err := build(attemptCtx)
+		if err != nil {
+			cancel()
+			return nil, errors.Wrap(err, "httpx: failed to build request")
+		}
+		c.applyHeaders(req)
+
+		start := time.Now()
+		resp, err := c.transport.Do(req)
+		observeRequest(rt.name, time.Since(start), err == nil)
+
+		if err == nil && resp.StatusCode < 500 {
+			rt.breaker.Success()
+			resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		rt.breaker.Failure()
+		if err == nil {
+			// A 5xx response: drain and close it before retrying so the connection can be reused.
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			lastErr = errors.Errorf("httpx: %s returned status %d", rt.name, resp.StatusCode)
+		} else {
+			lastErr = err
+		}
+		cancel()
+
+		if attempt == rt.policy.MaxAttempts-1 {
+			return nil, lastErr
+		}
+		select {
+		case <-time.After(resilience.Backoff(attempt, rt.policy.BaseDelay, rt.policy.MaxDelay)):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return nil, lastErr
+}
+
+func (c *Client) applyHeaders(req *http.Request) {
+	if c.headerRotator == nil {
+		return
+	}
+	for key, values := range c.headerRotator() {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+}
+
+// cancelOnCloseBody cancels the per-attempt timeout context once the response body is closed,
+// instead of the moment Do returns — the caller may still be streaming the body out.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// releaseOnCloseBody releases route's in-flight semaphore slot once the response body is closed.
+type releaseOnCloseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
@@ -0,0 +1,129 @@
+package httpx
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/resilience"
+)
+
+func newTestClient() *Client {
+	return New(&http.Client{}, nil)
+}
+
+func TestDoRetriesOnServerErrorThenSucceeds(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestClient()
+	c.Register("test", RoutePolicy{Policy: resilience.Policy{
+		Timeout: time.Second, MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond,
+	}})
+
+	resp, err := c.Do(context.Background(), "test", func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if atomic.LoadInt32(&attempts) != 3 {
+		t.Fatalf("expected exactly 3 attempts, got %d", attempts)
+	}
+}
+
+func TestDoFailsFastOnceBreakerTrips(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := newTestClient()
+	c.Register("test", RoutePolicy{Policy: resilience.Policy{
+		Timeout: time.Second, MaxAttempts: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond,
+		BreakerThreshold: 2, BreakerCooldown: time.Minute,
+	}})
+
+	build := func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Do(context.Background(), "test", build); err == nil {
+			t.Fatal("expected the 5xx response to surface as an error")
+		}
+	}
+
+	_, err := c.Do(context.Background(), "test", build)
+	if _, ok := errorCause(err).(*BreakerOpenError); !ok {
+		t.Fatalf("expected a *BreakerOpenError once the breaker trips, got %v (%T)", err, errorCause(err))
+	}
+}
+
+func TestDoBoundsInFlightRequests(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestClient()
+	c.Register("test", RoutePolicy{
+		Policy:      resilience.Policy{Timeout: 5 * time.Second, MaxAttempts: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+		MaxInFlight: 1,
+	})
+
+	build := func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		resp, err := c.Do(context.Background(), "test", build)
+		if err == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		close(done)
+	}()
+	<-started // the first request is now holding the only in-flight slot
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.Do(ctx, "test", build); err == nil {
+		t.Fatal("expected the second concurrent request to be blocked by MaxInFlight")
+	}
+
+	close(release)
+	<-done
+}
+
+// errorCause unwraps a github.com/pkg/errors-wrapped error down to its root cause.
+func errorCause(err error) error {
+	type causer interface{ Cause() error }
+	for {
+		c, ok := err.(causer)
+		if !ok {
+			return err
+		}
+		err = c.Cause()
+	}
+}
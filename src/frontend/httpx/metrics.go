@@ -0,0 +1,46 @@
+package httpx
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "frontend_httpx_requests_total",
+		Help: "Outbound HTTP requests made through httpx.Client, by route and outcome.",
+	}, []string{"route", "outcome"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "frontend_httpx_request_duration_seconds",
+		Help:    "Outbound HTTP request latency through httpx.Client, by route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route"})
+
+	breakerOpen = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "frontend_httpx_breaker_open",
+		Help: "1 if a route's circuit breaker is currently open (rejecting calls), else 0.",
+	}, []string{"route"})
+)
+
+func init() {
+	prometheus.MustRegister(requestsTotal, requestDuration, breakerOpen)
+}
+
+func observeRequest(route string, duration time.Duration, ok bool) {
+	outcome := "success"
+	if !ok {
+		outcome = "error"
+	}
+	requestsTotal.WithLabelValues(route, outcome).Inc()
+	requestDuration.WithLabelValues(route).Observe(duration.Seconds())
+}
+
+func observeBreakerState(route string, open bool) {
+	v := 0.0
+	if open {
+		v = 1.0
+	}
+	breakerOpen.WithLabelValues(route).Set(v)
+}
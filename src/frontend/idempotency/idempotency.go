@@ -0,0 +1,263 @@
+// Package idempotency lets an HTTP handler safely re-execute for the same logical request: a
+// caller that retries a POST (double-click, client timeout + retry) supplies the same
+// Idempotency-Key and gets back the original result instead of placing a second order.
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Record is what Guard persists once a keyed request has completed: enough to replay the
+// response verbatim on a later duplicate without re-running the handler. A Record with InFlight
+// set is a claim marker (see Store.Claim), not a completed result.
+type Record struct {
+	InFlight    bool
+	PayloadHash string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	SavedAt     time.Time
+}
+
+// Store is the minimal interface Guard needs from a backing cache. redisStore implements it
+// against Redis so a completed record survives a frontend restart and is visible across replicas;
+// tests use an in-memory fake.
+type Store interface {
+	// Load returns the record saved for (sessionID, key), or nil, nil if none exists yet. The
+	// returned Record may have InFlight set, meaning some caller (this process or another
+	// replica) has claimed the key but not yet saved a completed result.
+	Load(ctx context.Context, sessionID, key string) (*Record, error)
+	// Claim atomically marks (sessionID, key) in-flight, as long as no record (claimed or
+	// completed) already exists for it, so that at most one caller across every replica sharing
+	// this Store proceeds to run fn. It returns claimed=false if another caller already holds the
+	// claim or has completed the request first. ttl bounds how long a claim survives a crashed
+	// claimant before another caller may retry.
+	Claim(ctx context.Context, sessionID, key string, ttl time.Duration) (claimed bool, err error)
+	// Renew extends the TTL on an already-claimed (sessionID, key) without touching its value, so a
+	// claim survives for as long as its owner is still actively working rather than just for one
+	// fixed claimTTL window. It is a no-op if the key has already expired or been overwritten by
+	// Save.
+	Renew(ctx context.Context, sessionID, key string, ttl time.Duration) error
+	// Save persists rec for (sessionID, key) with the given TTL, replacing any claim marker.
+	Save(ctx context.Context, sessionID, key string, rec Record, ttl time.Duration) error
+}
+
+// Outcome reports what Guard.Run did with a keyed request.
+type Outcome int
+
+const (
+	// Executed means fn ran for the first time for this key.
+	Executed Outcome = iota
+	// Replayed means a prior completed request for this key matched payloadHash; fn was not
+	// called and the cached Result was returned instead.
+	Replayed
+	// Conflict means a prior completed request for this key exists with a different
+	// payloadHash; fn was not called.
+	Conflict
+	// InProgress means another caller (typically on a different replica) claimed this key and
+	// was still running fn when awaitCompletion gave up waiting; the caller should ask the client
+	// to retry rather than run fn again itself.
+	InProgress
+)
+
+// Result is the part of a handler's outcome Guard caches and can replay later.
+type Result struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// claimTTL bounds how long a claim (see Store.Claim) survives before another replica is allowed
+// to retry the key, if its owner stops renewing it: long enough to cover a crashed claimant being
+// noticed quickly, short enough that it doesn't wedge the key for the full record ttl (which is
+// usually measured in hours). A claimant still actively running fn keeps the claim alive past
+// claimTTL via renewClaim, so this does not bound how long a legitimately slow fn may run.
+const claimTTL = 30 * time.Second
+
+// renewInterval is how often a claim owner refreshes its claim's TTL while fn is still running.
+// It's a fraction of claimTTL so a missed renewal (GC pause, slow Redis round trip) still has a
+// couple of chances to land before the claim expires out from under a request that's still in
+// flight.
+const renewInterval = claimTTL / 3
+
+// pollInterval is how often awaitCompletion re-checks the Store while waiting for another
+// replica's claimed-but-not-yet-saved request to finish.
+const pollInterval = 100 * time.Millisecond
+
+// maxAwait bounds how long a caller will wait for another replica's in-flight request before
+// giving up and reporting InProgress. It's independent of claimTTL: a legitimately slow fn keeps
+// renewing its claim well past claimTTL, so this instead bounds how long it's reasonable to make
+// an HTTP caller wait before asking them to retry.
+const maxAwait = 2 * time.Minute
+
+// Guard serializes and caches keyed requests, both within this process and across replicas that
+// share the same Store. A per-key in-process mutex blocks concurrent duplicate submits from the
+// same replica so fn runs at most once per key even under a burst of retries; a Store-level claim
+// (Store.Claim) extends that guarantee across replicas behind a load balancer, since two replicas
+// each have their own independent in-process mutex and would otherwise both see no completed
+// record and both call fn.
+type Guard struct {
+	store Store
+	ttl   time.Duration
+
+	mu    sync.Mutex
+	locks map[string]*lockEntry
+}
+
+// lockEntry pairs a key's in-process mutex with the time it was last used, so keyMutex can evict
+// entries for keys that haven't been touched in a while (see evictExpiredLocksLocked) instead of
+// g.locks growing by one *sync.Mutex per distinct (sessionID, key) ever seen for the life of the
+// process.
+type lockEntry struct {
+	mu       sync.Mutex
+	lastUsed time.Time
+}
+
+// NewGuard builds a Guard backed by store, caching completed results for ttl.
+func NewGuard(store Store, ttl time.Duration) *Guard {
+	return &Guard{store: store, ttl: ttl, locks: make(map[string]*lockEntry)}
+}
+
+// Run executes fn under idempotency protection for (sessionID, key). If a completed request for
+// this key already exists, Run returns it without calling fn: Replayed if payloadHash matches the
+// original, Conflict if it doesn't. If another caller has claimed the key and not yet finished,
+// Run waits briefly for that caller's result rather than running fn itself. Otherwise fn runs and,
+// on success, its Result is saved for future replays. If the Store is unreachable, Run fails open
+// and just calls fn.
+func (g *Guard) Run(ctx context.Context, sessionID, key, payloadHash string, fn func() (Result, error)) (Result, Outcome, error) {
+	mu := g.keyMutex(sessionID, key)
+	mu.Lock()
+	defer mu.Unlock()
+
+	existing, err := g.store.Load(ctx, sessionID, key)
+	if err != nil {
+		result, fnErr := fn()
+		return result, Executed, fnErr
+	}
+	if existing != nil && existing.InFlight {
+		return g.awaitCompletion(ctx, sessionID, key, payloadHash)
+	}
+	if existing != nil {
+		return replayOrConflict(*existing, payloadHash), boolOutcome(existing.PayloadHash == payloadHash), nil
+	}
+
+	claimed, err := g.store.Claim(ctx, sessionID, key, claimTTL)
+	if err != nil {
+		result, fnErr := fn()
+		return result, Executed, fnErr
+	}
+	if !claimed {
+		return g.awaitCompletion(ctx, sessionID, key, payloadHash)
+	}
+
+	stopRenew := g.renewClaimUntilDone(sessionID, key)
+	result, err := fn()
+	stopRenew()
+	if err != nil {
+		// Don't cache a failed attempt: the client should be able to retry with the same key. The
+		// claim itself expires after claimTTL once renewal stops, so a retry isn't blocked waiting
+		// on it.
+		return result, Executed, err
+	}
+
+	_ = g.store.Save(ctx, sessionID, key, Record{
+		PayloadHash: payloadHash,
+		StatusCode:  result.StatusCode,
+		ContentType: result.ContentType,
+		Body:        result.Body,
+		SavedAt:     time.Now(),
+	}, g.ttl)
+	return result, Executed, nil
+}
+
+// renewClaimUntilDone keeps the claim for (sessionID, key) alive by extending its TTL every
+// renewInterval until the returned stop func is called, so a claimTTL expiry never races a fn
+// that's still genuinely running. The caller must call stop once fn returns.
+func (g *Guard) renewClaimUntilDone(sessionID, key string) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(renewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				_ = g.store.Renew(context.Background(), sessionID, key, claimTTL)
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// awaitCompletion polls the Store for the completed record another caller is expected to save. It
+// reports InProgress (rather than erroring) once maxAwait elapses without one appearing, since the
+// other caller may simply be slow, not gone: maxAwait bounds how long it's reasonable to make this
+// caller wait, independent of claimTTL, which the claim owner keeps renewing for as long as it's
+// actually still working.
+func (g *Guard) awaitCompletion(ctx context.Context, sessionID, key, payloadHash string) (Result, Outcome, error) {
+	deadline := time.Now().Add(maxAwait)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return Result{}, InProgress, nil
+		case <-ticker.C:
+			existing, err := g.store.Load(ctx, sessionID, key)
+			if err != nil || existing == nil || existing.InFlight {
+				if time.Now().After(deadline) {
+					return Result{}, InProgress, nil
+				}
+				continue
+			}
+			return replayOrConflict(*existing, payloadHash), boolOutcome(existing.PayloadHash == payloadHash), nil
+		}
+	}
+}
+
+func replayOrConflict(rec Record, payloadHash string) Result {
+	if rec.PayloadHash != payloadHash {
+		return Result{}
+	}
+	return Result{StatusCode: rec.StatusCode, ContentType: rec.ContentType, Body: rec.Body}
+}
+
+func boolOutcome(payloadMatches bool) Outcome {
+	if payloadMatches {
+		return Replayed
+	}
+	return Conflict
+}
+
+func (g *Guard) keyMutex(sessionID, key string) *sync.Mutex {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.evictExpiredLocksLocked()
+
+	k := sessionID + ":" + key
+	entry, ok := g.locks[k]
+	if !ok {
+		entry = &lockEntry{}
+		g.locks[k] = entry
+	}
+	entry.lastUsed = time.Now()
+	return &entry.mu
+}
+
+// evictExpiredLocksLocked drops lock entries idle for longer than g.ttl: once a key's completed
+// record (if any) would itself have expired from the Store, there's no remaining reason to keep
+// serializing requests for it in this process, so without this g.locks would grow by one entry
+// per distinct (sessionID, key) ever seen and never shrink. Callers must hold g.mu.
+func (g *Guard) evictExpiredLocksLocked() {
+	cutoff := time.Now().Add(-g.ttl)
+	for k, entry := range g.locks {
+		if entry.lastUsed.Before(cutoff) {
+			delete(g.locks, k)
+		}
+	}
+}
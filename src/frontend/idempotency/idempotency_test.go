@@ -0,0 +1,202 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeStore is an in-memory Store, enough to exercise Guard's decision logic without a live Redis
+// instance.
+type fakeStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{records: make(map[string]Record)}
+}
+
+func (s *fakeStore) Load(ctx context.Context, sessionID, key string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[sessionID+":"+key]
+	if !ok {
+		return nil, nil
+	}
+	return &rec, nil
+}
+
+func (s *fakeStore) Claim(ctx context.Context, sessionID, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := sessionID + ":" + key
+	if _, ok := s.records[k]; ok {
+		return false, nil
+	}
+	s.records[k] = Record{InFlight: true, SavedAt: time.Now()}
+	return true, nil
+}
+
+func (s *fakeStore) Renew(ctx context.Context, sessionID, key string, ttl time.Duration) error {
+	return nil
+}
+
+func (s *fakeStore) Save(ctx context.Context, sessionID, key string, rec Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[sessionID+":"+key] = rec
+	return nil
+}
+
+func TestGuardRunExecutesOnceForConcurrentDuplicates(t *testing.T) {
+	g := NewGuard(newFakeStore(), 24*time.Hour)
+
+	var calls int32
+	fn := func() (Result, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+		return Result{StatusCode: 200, ContentType: "text/html", Body: []byte("order placed")}, nil
+	}
+
+	const n = 10
+	var wg sync.WaitGroup
+	outcomes := make([]Outcome, n)
+	results := make([]Result, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, outcome, err := g.Run(context.Background(), "session-1", "key-1", "hash-a", fn)
+			if err != nil {
+				t.Errorf("Run: %v", err)
+			}
+			outcomes[i] = outcome
+			results[i] = result
+		}(i)
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("expected fn to run exactly once for concurrent duplicates, ran %d times", got)
+	}
+	executed, replayed := 0, 0
+	for i, outcome := range outcomes {
+		switch outcome {
+		case Executed:
+			executed++
+		case Replayed:
+			replayed++
+		default:
+			t.Fatalf("request %d got unexpected outcome %v", i, outcome)
+		}
+		if string(results[i].Body) != "order placed" {
+			t.Fatalf("request %d got unexpected body %q", i, results[i].Body)
+		}
+	}
+	if executed != 1 || replayed != n-1 {
+		t.Fatalf("expected 1 executed and %d replayed, got %d executed and %d replayed", n-1, executed, replayed)
+	}
+}
+
+func TestGuardRunExecutesOnceAcrossReplicas(t *testing.T) {
+	// Two Guards sharing one Store stand in for two frontend replicas behind a load balancer: each
+	// has its own in-process keyMutex, so only the shared Store's Claim can prevent both from
+	// running fn for the same duplicate submit.
+	store := newFakeStore()
+	g1 := NewGuard(store, 24*time.Hour)
+	g2 := NewGuard(store, 24*time.Hour)
+
+	var calls int32
+	fn := func() (Result, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(20 * time.Millisecond)
+		return Result{StatusCode: 200, ContentType: "text/html", Body: []byte("order placed")}, nil
+	}
+
+	var wg sync.WaitGroup
+	outcomes := make([]Outcome, 2)
+	results := make([]Result, 2)
+	for i, g := range []*Guard{g1, g2} {
+		wg.Add(1)
+		go func(i int, g *Guard) {
+			defer wg.Done()
+			result, outcome, err := g.Run(context.Background(), "session-1", "key-1", "hash-a", fn)
+			if err != nil {
+				t.Errorf("Run: %v", err)
+			}
+			outcomes[i] = outcome
+			results[i] = result
+		}(i, g)
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("expected fn to run exactly once across replicas, ran %d times", got)
+	}
+	executed, replayed := 0, 0
+	for i, outcome := range outcomes {
+		switch outcome {
+		case Executed:
+			executed++
+		case Replayed:
+			replayed++
+		default:
+			t.Fatalf("replica %d got unexpected outcome %v", i, outcome)
+		}
+		if string(results[i].Body) != "order placed" {
+			t.Fatalf("replica %d got unexpected body %q", i, results[i].Body)
+		}
+	}
+	if executed != 1 || replayed != 1 {
+		t.Fatalf("expected 1 executed and 1 replayed across replicas, got %d executed and %d replayed", executed, replayed)
+	}
+}
+
+func TestGuardRunReturnsConflictForMismatchedPayload(t *testing.T) {
+	g := NewGuard(newFakeStore(), 24*time.Hour)
+	fn := func() (Result, error) {
+		return Result{StatusCode: 200, Body: []byte("ok")}, nil
+	}
+
+	if _, outcome, err := g.Run(context.Background(), "session-1", "key-1", "hash-a", fn); err != nil || outcome != Executed {
+		t.Fatalf("expected first request to execute, got outcome=%v err=%v", outcome, err)
+	}
+
+	_, outcome, err := g.Run(context.Background(), "session-1", "key-1", "hash-b", fn)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if outcome != Conflict {
+		t.Fatalf("expected a mismatched payload hash to conflict, got %v", outcome)
+	}
+}
+
+func TestGuardRunDoesNotCacheAFailedAttempt(t *testing.T) {
+	g := NewGuard(newFakeStore(), 24*time.Hour)
+
+	var calls int32
+	failThenSucceed := func() (Result, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return Result{}, context.DeadlineExceeded
+		}
+		return Result{StatusCode: 200, Body: []byte("ok")}, nil
+	}
+
+	if _, outcome, err := g.Run(context.Background(), "session-1", "key-1", "hash-a", failThenSucceed); err == nil || outcome != Executed {
+		t.Fatalf("expected the first attempt to fail, got outcome=%v err=%v", outcome, err)
+	}
+
+	result, outcome, err := g.Run(context.Background(), "session-1", "key-1", "hash-a", failThenSucceed)
+	if err != nil {
+		t.Fatalf("expected a retry after a failed attempt to succeed, got %v", err)
+	}
+	if outcome != Executed {
+		t.Fatalf("expected a retry after a failed attempt to re-execute, got %v", outcome)
+	}
+	if string(result.Body) != "ok" {
+		t.Fatalf("unexpected body %q", result.Body)
+	}
+}
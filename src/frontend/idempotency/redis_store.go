@@ -0,0 +1,73 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisStore implements Store against Redis: each record is a JSON blob at a key scoped to the
+// session and the caller-supplied Idempotency-Key, expiring after its TTL.
+type redisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore builds a Store backed by a live Redis connection.
+func NewRedisStore(client *redis.Client) Store {
+	return &redisStore{client: client}
+}
+
+func (s *redisStore) Load(ctx context.Context, sessionID, key string) (*Record, error) {
+	raw, err := s.client.Get(ctx, redisKey(sessionID, key)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "idempotency: failed to load record")
+	}
+	var rec Record
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return nil, errors.Wrap(err, "idempotency: failed to decode record")
+	}
+	return &rec, nil
+}
+
+func (s *redisStore) Claim(ctx context.Context, sessionID, key string, ttl time.Duration) (bool, error) {
+	raw, err := json.Marshal(Record{InFlight: true, SavedAt: time.Now()})
+	if err != nil {
+		return false, errors.Wrap(err, "idempotency: failed to encode claim")
+	}
+	// SetNX only writes if the key doesn't already exist, so exactly one of every caller racing
+	// on the same (sessionID, key) across every replica sharing this Redis instance wins the
+	// claim; everyone else is told claimed=false and falls back to polling Load.
+	ok, err := s.client.SetNX(ctx, redisKey(sessionID, key), raw, ttl).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "idempotency: failed to claim record")
+	}
+	return ok, nil
+}
+
+func (s *redisStore) Renew(ctx context.Context, sessionID, key string, ttl time.Duration) error {
+	if err := s.client.Expire(ctx, redisKey(sessionID, key), ttl).Err(); err != nil {
+		return errors.Wrap(err, "idempotency: failed to renew claim")
+	}
+	return nil
+}
+
+func (s *redisStore) Save(ctx context.Context, sessionID, key string, rec Record, ttl time.Duration) error {
+	raw, err := json.Marshal(rec)
+	if err != nil {
+		return errors.Wrap(err, "idempotency: failed to encode record")
+	}
+	if err := s.client.Set(ctx, redisKey(sessionID, key), raw, ttl).Err(); err != nil {
+		return errors.Wrap(err, "idempotency: failed to save record")
+	}
+	return nil
+}
+
+func redisKey(sessionID, key string) string {
+	return "idempotency:" + sessionID + ":" + key
+}
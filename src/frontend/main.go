@@ -2,21 +2,44 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"cloud.google.com/go/profiler"
+	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 
-	// Datadog native tracing
-	grpctrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/google.golang.org/grpc"
-	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/gorilla/mux"
-	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/bandit"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/events"
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/frontend/genproto"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/httpx"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/idempotency"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/middleware"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/moderation"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/packaging"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/productsearch"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/resilience"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/telemetry"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/tus"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/video"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/videohub"
 )
 
 const (
@@ -27,6 +50,14 @@ const (
 	cookiePrefix    = "shop_"
 	cookieSessionID = cookiePrefix + "session-id"
 	cookieCurrency  = cookiePrefix + "currency"
+
+	// httpx route names for svc.tryOnClient/svc.videoGenClient (see handlers.go's try-on and
+	// video-generation handlers).
+	routeTryOn            = "try_on"
+	routeVideoGenGenerate = "video_gen_generate"
+	routeVideoGenStatus   = "video_gen_status"
+	routeVideoGenValidate = "video_gen_validate"
+	routeVideoGenSource   = "video_gen_source"
 )
 
 var (
@@ -53,24 +84,114 @@ type Notification struct {
 	Read      bool      `json:"read"`
 }
 
-// NotificationStore manages notifications per session
-type NotificationStore struct {
+// maxNotificationSubscribersPerSession bounds how many concurrent Subscribe channels a single
+// session may hold open at once, so a browser tab that reconnects to notificationsStreamHandler
+// without cleanly closing its old stream can't leak channels/goroutines without bound.
+const maxNotificationSubscribersPerSession = 8
+
+// notificationSubscriber is one live Subscribe call's delivery channel.
+type notificationSubscriber struct {
+	ch chan *Notification
+}
+
+// NotificationStore persists PEAU agent notifications per session and pushes new ones to every
+// live Subscribe channel for that session (see notificationsStreamHandler). memoryStore is the
+// original in-process implementation; redisStore (see notifications_redis.go) persists to Redis
+// and fans new notifications out to every frontend replica, selected via NOTIFICATION_STORE.
+type NotificationStore interface {
+	// AddNotification records a notification for sessionID and delivers it to that session's
+	// live subscribers.
+	AddNotification(ctx context.Context, sessionID, userID, message string)
+	// GetNotifications returns every notification recorded for sessionID, oldest first.
+	GetNotifications(ctx context.Context, sessionID string) []*Notification
+	// MarkAsRead marks the notification with the given ID as read, if it exists.
+	MarkAsRead(ctx context.Context, sessionID, notificationID string)
+	// Subscribe registers a live per-session channel that AddNotification delivers new
+	// notifications to, for a push-based alternative to GetNotifications' poll. The returned
+	// func unsubscribes and must be called once the caller is done with the channel.
+	Subscribe(sessionID string) (<-chan *Notification, func())
+}
+
+// localFanout is the in-process "push" half shared by every NotificationStore implementation: it
+// holds each session's live Subscribe channels and delivers notifications to them. What differs
+// between memoryStore and redisStore is how notifications reach deliver in the first place —
+// directly for memoryStore, over Redis pub/sub (so every replica's localFanout sees the same
+// stream) for redisStore.
+type localFanout struct {
+	mu          sync.RWMutex
+	subscribers map[string][]*notificationSubscriber // sessionID -> live Subscribe channels
+}
+
+func newLocalFanout() *localFanout {
+	return &localFanout{subscribers: make(map[string][]*notificationSubscriber)}
+}
+
+// Subscribing past maxNotificationSubscribersPerSession evicts the session's oldest subscriber.
+func (f *localFanout) Subscribe(sessionID string) (<-chan *Notification, func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	subs := f.subscribers[sessionID]
+	if len(subs) >= maxNotificationSubscribersPerSession {
+		close(subs[0].ch)
+		subs = append([]*notificationSubscriber(nil), subs[1:]...)
+	}
+
+	sub := &notificationSubscriber{ch: make(chan *Notification, 16)}
+	f.subscribers[sessionID] = append(subs, sub)
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			remaining := f.subscribers[sessionID]
+			for i, s := range remaining {
+				if s == sub {
+					f.subscribers[sessionID] = append(remaining[:i], remaining[i+1:]...)
+					close(s.ch)
+					return
+				}
+			}
+		})
+	}
+	return sub.ch, unsubscribe
+}
+
+func (f *localFanout) deliver(sessionID string, n *Notification) {
+	f.mu.RLock()
+	subs := append([]*notificationSubscriber(nil), f.subscribers[sessionID]...)
+	f.mu.RUnlock()
+
+	for _, sub := range subs {
+		select {
+		case sub.ch <- n:
+		default:
+			// Slow subscriber: drop rather than block the caller that's adding the
+			// notification. It still sees the notification via the polling path.
+		}
+	}
+}
+
+// memoryStore is the original NotificationStore implementation: notification history lives only
+// in this process's memory, so it's lost on restart and isn't shared across replicas. Use
+// redisStore for that.
+type memoryStore struct {
+	*localFanout
+
 	mu            sync.RWMutex
 	notifications map[string][]*Notification // sessionID -> notifications
 }
 
-// NewNotificationStore creates a new notification store
-func NewNotificationStore() *NotificationStore {
-	return &NotificationStore{
+// newMemoryStore creates a new in-process notification store.
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		localFanout:   newLocalFanout(),
 		notifications: make(map[string][]*Notification),
 	}
 }
 
-// AddNotification adds a notification for a session
-func (ns *NotificationStore) AddNotification(sessionID, userID, message string) {
-	ns.mu.Lock()
-	defer ns.mu.Unlock()
-
+func (ms *memoryStore) AddNotification(_ context.Context, sessionID, userID, message string) {
 	notification := &Notification{
 		ID:        fmt.Sprintf("%s_%d", sessionID, time.Now().UnixNano()),
 		Message:   message,
@@ -79,15 +200,18 @@ func (ns *NotificationStore) AddNotification(sessionID, userID, message string)
 		Read:      false,
 	}
 
-	ns.notifications[sessionID] = append(ns.notifications[sessionID], notification)
+	ms.mu.Lock()
+	ms.notifications[sessionID] = append(ms.notifications[sessionID], notification)
+	ms.mu.Unlock()
+
+	ms.deliver(sessionID, notification)
 }
 
-// GetNotifications returns all notifications for a session
-func (ns *NotificationStore) GetNotifications(sessionID string) []*Notification {
-	ns.mu.RLock()
-	defer ns.mu.RUnlock()
+func (ms *memoryStore) GetNotifications(_ context.Context, sessionID string) []*Notification {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
 
-	notifications := ns.notifications[sessionID]
+	notifications := ms.notifications[sessionID]
 	if notifications == nil {
 		return []*Notification{}
 	}
@@ -98,12 +222,11 @@ func (ns *NotificationStore) GetNotifications(sessionID string) []*Notification
 	return result
 }
 
-// MarkAsRead marks a notification as read
-func (ns *NotificationStore) MarkAsRead(sessionID, notificationID string) {
-	ns.mu.Lock()
-	defer ns.mu.Unlock()
+func (ms *memoryStore) MarkAsRead(_ context.Context, sessionID, notificationID string) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
 
-	notifications := ns.notifications[sessionID]
+	notifications := ms.notifications[sessionID]
 	for _, notification := range notifications {
 		if notification.ID == notificationID {
 			notification.Read = true
@@ -115,24 +238,31 @@ func (ns *NotificationStore) MarkAsRead(sessionID, notificationID string) {
 type frontendServer struct {
 	productCatalogSvcAddr string
 	productCatalogSvcConn *grpc.ClientConn
+	productCatalogClient  pb.ProductCatalogServiceClient
 
 	currencySvcAddr string
 	currencySvcConn *grpc.ClientConn
+	currencyClient  pb.CurrencyServiceClient
 
 	cartSvcAddr string
 	cartSvcConn *grpc.ClientConn
+	cartClient  pb.CartServiceClient
 
 	recommendationSvcAddr string
 	recommendationSvcConn *grpc.ClientConn
+	recommendationClient  pb.RecommendationServiceClient
 
 	checkoutSvcAddr string
 	checkoutSvcConn *grpc.ClientConn
+	checkoutClient  pb.CheckoutServiceClient
 
 	shippingSvcAddr string
 	shippingSvcConn *grpc.ClientConn
+	shippingClient  pb.ShippingServiceClient
 
 	adSvcAddr string
 	adSvcConn *grpc.ClientConn
+	adClient  pb.AdServiceClient
 
 	shoppingAssistantSvcAddr string
 	tryOnSvcAddr             string
@@ -140,8 +270,76 @@ type frontendServer struct {
 	peauAgentSvcAddr         string
 	videoGenerationSvcAddr   string
 
-	// Notification store for PEAU agent responses
-	notifications *NotificationStore
+	// Notification store for PEAU agent responses. memoryStore by default; redisStore (persistent,
+	// multi-replica) when NOTIFICATION_STORE=redis.
+	notifications NotificationStore
+
+	// packagingClient streams packaging lookups over gRPC, falling back to HTTP.
+	packagingClient *packaging.Client
+
+	// httpClient is shared (with a pooled Transport) by every outbound HTTP call to the
+	// PEAU/chatbot/try-on/video-generation/packaging-fallback services, instead of each call site
+	// constructing its own short-lived http.Client. Use httpClientWithTimeout for calls that need a
+	// timeout other than the transport default.
+	httpClient *http.Client
+
+	// events is the behavior-event bus (see the events package): buffered, batched, and backed by
+	// an HTTP/Kafka/Pub/Sub sink selected via BEHAVIOR_EVENT_SINK, replacing the old trackBehavior.
+	events events.EventProducer
+
+	// adBandit ranks ads (see the bandit package) via Thompson sampling instead of chooseAd's old
+	// uniform rand.Intn pick.
+	adBandit *bandit.Bandit
+
+	// rateLimiter enforces per-route-class, per-session/IP request limits (see the middleware
+	// package), backed by Redis so limits survive a restart and are shared across replicas. nil
+	// when RATE_LIMIT_REDIS_ADDR is unset, in which case every route is left unlimited.
+	rateLimiter *middleware.RateLimiter
+
+	// tryOnSemaphore caps concurrent in-flight try-on requests per session so one user can't
+	// exhaust the shared GPU try-on backend.
+	tryOnSemaphore *middleware.SessionSemaphore
+
+	// idempotency guards placeOrderHandler against duplicate submits (double-click, client
+	// retry after a timeout) re-placing the same order. nil when IDEMPOTENCY_REDIS_ADDR is
+	// unset, in which case placeOrderHandler runs unprotected.
+	idempotency *idempotency.Guard
+
+	// videoHub fans a single upstream video-generation progress subscription out to every local
+	// client watching the same job_id (see videoEventsHandler), instead of one upstream
+	// connection per browser tab.
+	videoHub *videohub.Hub
+
+	// videoStore caches each job's source MP4 and lazily-transcoded HLS renditions on disk (see
+	// serveVideoHandler and the video package).
+	videoStore *video.Store
+
+	// moderation tracks the review state of generated ad videos and their audit log (see
+	// adminModerationListHandler/adminModerationDecisionHandler). Always set: an in-memory store
+	// when MODERATION_DB_DSN is unset, so the admin UI is functional even without a database.
+	moderation moderation.Store
+
+	// tusStore backs the resumable upload endpoint (see tusHandler/tryOnHandler's X-Upload-Id
+	// path), so a large base_image survives a dropped mobile connection without being re-sent
+	// from byte zero.
+	tusStore   *tus.FileStore
+	tusHandler *tus.Handler
+
+	// tryOnClient and videoGenClient wrap the try-on and video-generation services' plain-HTTP
+	// calls with per-route timeout/retry/circuit-breaker/concurrency policy (see the httpx
+	// package), replacing the old ad-hoc httpClientWithTimeout call sites in handlers.go.
+	tryOnClient    *httpx.Client
+	videoGenClient *httpx.Client
+
+	// productSearch fans searchProductsForAdsHandler's query out to the product catalog and any
+	// configured external providers in parallel (see the productsearch package), replacing the
+	// old single-upstream call to the video-generation service's own search endpoint.
+	productSearch *productsearch.Aggregator
+
+	// shuttingDown flips true as soon as a shutdown signal is received (see main()), before the
+	// HTTP server actually stops accepting connections, so readyHandler fails fast and the load
+	// balancer stops routing new traffic here while in-flight requests drain.
+	shuttingDown atomic.Bool
 }
 
 func main() {
@@ -159,14 +357,54 @@ func main() {
 	log.Out = os.Stdout
 
 	svc := new(frontendServer)
-	svc.notifications = NewNotificationStore()
+	notifStore, err := newNotificationStoreFromEnv(ctx, log)
+	if err != nil {
+		log.WithError(err).Fatal("failed to create notification store")
+	}
+	svc.notifications = notifStore
+	svc.httpClient = &http.Client{
+		Transport: &http.Transport{
+			MaxIdleConns:        100,
+			MaxIdleConnsPerHost: 10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+	svc.adBandit = bandit.New(bandit.NewMemoryStore(adBanditDecayInterval(), adBanditDecayFactor()))
+	svc.tryOnSemaphore = middleware.NewSessionSemaphore(middleware.TryOnMaxConcurrentPerSessionFromEnv())
+	if addr := os.Getenv("RATE_LIMIT_REDIS_ADDR"); addr != "" {
+		redisClient := redis.NewClient(&redis.Options{Addr: addr})
+		svc.rateLimiter = middleware.NewRedisRateLimiter(redisClient, middleware.ClassesFromEnv())
+	} else {
+		log.Warn("RATE_LIMIT_REDIS_ADDR not set, running without request rate limiting")
+	}
+	if addr := os.Getenv("IDEMPOTENCY_REDIS_ADDR"); addr != "" {
+		redisClient := redis.NewClient(&redis.Options{Addr: addr})
+		svc.idempotency = idempotency.NewGuard(idempotency.NewRedisStore(redisClient), idempotencyTTL())
+	} else {
+		log.Warn("IDEMPOTENCY_REDIS_ADDR not set, running without place-order idempotency protection")
+	}
+
+	packagingClient, err := newPackagingClient()
+	if err != nil {
+		log.WithError(err).Fatal("failed to create packaging client")
+	}
+	svc.packagingClient = packagingClient
 
 	baseUrl = os.Getenv("BASE_URL")
 
+	var otelShutdown telemetry.Shutdown
 	if os.Getenv("ENABLE_TRACING") == "1" {
 		log.Info("Tracing enabled.")
-		initTracing(log)
-		defer tracer.Stop()
+		shutdown, err := telemetry.Init(ctx, otelServiceName())
+		if err != nil {
+			log.WithError(err).Fatal("failed to initialize OpenTelemetry")
+		}
+		otelShutdown = shutdown
+		defer func() {
+			if err := otelShutdown(context.Background()); err != nil {
+				log.WithError(err).Warn("failed to cleanly shut down OpenTelemetry")
+			}
+		}()
 	} else {
 		log.Info("Tracing disabled.")
 	}
@@ -195,90 +433,207 @@ func main() {
 	mustMapEnv(&svc.chatbotSvcAddr, "CHATBOT_SERVICE_ADDR")
 	mustMapEnv(&svc.peauAgentSvcAddr, "PEAU_AGENT_SERVICE_ADDR")
 	mustMapEnv(&svc.videoGenerationSvcAddr, "VIDEO_GENERATION_SERVICE_ADDR")
+	svc.videoHub = videohub.New(svc.openVideoEventsUpstream)
+
+	svc.tryOnClient = httpx.New(svc.httpClient, nil)
+	tryOnPolicy := httpx.RoutePolicyFromEnv("TRY_ON_SERVICE")
+	tryOnPolicy.MaxAttempts = 1 // the request body streams product/base images once and can't be replayed
+	svc.tryOnClient.Register(routeTryOn, tryOnPolicy)
+
+	svc.videoGenClient = httpx.New(svc.httpClient, nil)
+	videoGenPolicy := httpx.RoutePolicyFromEnv("VIDEO_GENERATION_SERVICE")
+	svc.videoGenClient.Register(routeVideoGenStatus, videoGenPolicy)
+	svc.videoGenClient.Register(routeVideoGenValidate, videoGenPolicy)
+	svc.videoGenClient.Register(routeVideoGenSource, videoGenPolicy)
+	videoGenGeneratePolicy := httpx.RoutePolicyFromEnv("VIDEO_GENERATION_SERVICE_GENERATE")
+	videoGenGeneratePolicy.Timeout = videoGenerateTimeout()
+	svc.videoGenClient.Register(routeVideoGenGenerate, videoGenGeneratePolicy)
+
+	videoStore, err := video.NewStore(videoCacheDir(), video.NewTranscoder(videoFFmpegPath()))
+	if err != nil {
+		log.WithError(err).Fatal("failed to create video rendition cache")
+	}
+	svc.videoStore = videoStore
+	go svc.videoStore.StartGC(ctx, videoCacheGCInterval(), videoCacheMaxBytes(), log)
+
+	moderationStore, err := newModerationStore(ctx)
+	if err != nil {
+		log.WithError(err).Fatal("failed to create moderation store")
+	}
+	svc.moderation = moderationStore
 
-	mustConnGRPC(ctx, &svc.currencySvcConn, svc.currencySvcAddr)
-	mustConnGRPC(ctx, &svc.productCatalogSvcConn, svc.productCatalogSvcAddr)
-	mustConnGRPC(ctx, &svc.cartSvcConn, svc.cartSvcAddr)
-	mustConnGRPC(ctx, &svc.recommendationSvcConn, svc.recommendationSvcAddr)
-	mustConnGRPC(ctx, &svc.shippingSvcConn, svc.shippingSvcAddr)
-	mustConnGRPC(ctx, &svc.checkoutSvcConn, svc.checkoutSvcAddr)
-	mustConnGRPC(ctx, &svc.adSvcConn, svc.adSvcAddr)
-
-	// Create Datadog-traced mux router
-	r := httptrace.NewRouter()
-	r.HandleFunc(baseUrl+"/", svc.homeHandler).Methods(http.MethodGet, http.MethodHead)
-	r.HandleFunc(baseUrl+"/product/{id}", svc.productHandler).Methods(http.MethodGet, http.MethodHead)
+	tusStore, err := tus.NewFileStore(tusUploadDir(), tusUploadExpiry())
+	if err != nil {
+		log.WithError(err).Fatal("failed to create resumable upload store")
+	}
+	svc.tusStore = tusStore
+	svc.tusHandler = &tus.Handler{Store: tusStore, MaxSize: tusMaxUploadBytes()}
+	go tus.StartGC(ctx, tusStore, tus.DefaultGCInterval, log)
+
+	eventProducer, err := events.NewProducerFromEnv(ctx, "http://"+svc.peauAgentSvcAddr+"/track_behavior", svc.httpClient, log)
+	if err != nil {
+		log.WithError(err).Fatal("failed to create behavior event producer")
+	}
+	svc.events = eventProducer
+
+	dialBackend(ctx, &svc.currencySvcConn, "currency", svc.currencySvcAddr, "CURRENCY_SERVICE", log)
+	dialBackend(ctx, &svc.productCatalogSvcConn, "product_catalog", svc.productCatalogSvcAddr, "PRODUCT_CATALOG_SERVICE", log)
+	dialBackend(ctx, &svc.cartSvcConn, "cart", svc.cartSvcAddr, "CART_SERVICE", log)
+	dialBackend(ctx, &svc.recommendationSvcConn, "recommendation", svc.recommendationSvcAddr, "RECOMMENDATION_SERVICE", log)
+	dialBackend(ctx, &svc.shippingSvcConn, "shipping", svc.shippingSvcAddr, "SHIPPING_SERVICE", log)
+	dialBackend(ctx, &svc.checkoutSvcConn, "checkout", svc.checkoutSvcAddr, "CHECKOUT_SERVICE", log)
+	dialBackend(ctx, &svc.adSvcConn, "ad", svc.adSvcAddr, "AD_SERVICE", log)
+
+	svc.currencyClient = pb.NewCurrencyServiceClient(svc.currencySvcConn)
+	svc.productCatalogClient = pb.NewProductCatalogServiceClient(svc.productCatalogSvcConn)
+	svc.cartClient = pb.NewCartServiceClient(svc.cartSvcConn)
+	svc.recommendationClient = pb.NewRecommendationServiceClient(svc.recommendationSvcConn)
+	svc.shippingClient = pb.NewShippingServiceClient(svc.shippingSvcConn)
+	svc.checkoutClient = pb.NewCheckoutServiceClient(svc.checkoutSvcConn)
+	svc.adClient = pb.NewAdServiceClient(svc.adSvcConn)
+
+	svc.productSearch = svc.newProductSearchAggregator()
+
+	r := mux.NewRouter()
+	r.Use(routeMetricsMiddleware)
+	r.HandleFunc(baseUrl+"/", svc.rateLimited(middleware.ClassBrowse, svc.homeHandler)).Methods(http.MethodGet, http.MethodHead)
+	r.HandleFunc(baseUrl+"/product/{id}", svc.rateLimited(middleware.ClassBrowse, svc.productHandler)).Methods(http.MethodGet, http.MethodHead)
 	r.HandleFunc(baseUrl+"/cart", svc.viewCartHandler).Methods(http.MethodGet, http.MethodHead)
-	r.HandleFunc(baseUrl+"/cart", svc.addToCartHandler).Methods(http.MethodPost)
-	r.HandleFunc(baseUrl+"/cart/empty", svc.emptyCartHandler).Methods(http.MethodPost)
-	r.HandleFunc(baseUrl+"/setCurrency", svc.setCurrencyHandler).Methods(http.MethodPost)
+	r.HandleFunc(baseUrl+"/cart", svc.requireCSRF(svc.addToCartHandler)).Methods(http.MethodPost)
+	r.HandleFunc(baseUrl+"/cart/empty", svc.requireCSRF(svc.emptyCartHandler)).Methods(http.MethodPost)
+	r.HandleFunc(baseUrl+"/setCurrency", svc.requireCSRF(svc.setCurrencyHandler)).Methods(http.MethodPost)
 	r.HandleFunc(baseUrl+"/logout", svc.logoutHandler).Methods(http.MethodGet)
-	r.HandleFunc(baseUrl+"/cart/checkout", svc.placeOrderHandler).Methods(http.MethodPost)
+	r.HandleFunc(baseUrl+"/cart/checkout", svc.requireCSRF(svc.placeOrderHandler)).Methods(http.MethodPost)
 	r.HandleFunc(baseUrl+"/assistant", svc.assistantHandler).Methods(http.MethodGet)
-	r.HandleFunc(baseUrl+"/tryon", svc.tryOnHandler).Methods(http.MethodPost)
+	r.HandleFunc(baseUrl+"/tryon", svc.requireCSRF(svc.rateLimited(middleware.ClassTryOn, svc.tryOnHandler))).Methods(http.MethodPost)
 	r.HandleFunc(baseUrl+"/generate-ads", svc.generateAdsHandler).Methods(http.MethodGet)
 	r.HandleFunc(baseUrl+"/admin", svc.homeHandler).Methods(http.MethodGet) // Admin route now renders homeHandler
 	r.HandleFunc(baseUrl+"/admin/generate-ads", svc.generateAdsHandler).Methods(http.MethodGet)
 	r.HandleFunc(baseUrl+"/api/products/search", svc.searchProductsForAdsHandler).Methods(http.MethodGet)
-	r.HandleFunc(baseUrl+"/api/generate-video", svc.generateVideoHandler).Methods(http.MethodPost)
+	r.HandleFunc(baseUrl+"/api/generate-video", svc.requireCSRF(svc.generateVideoHandler)).Methods(http.MethodPost)
 	r.HandleFunc(baseUrl+"/api/video-status/{job_id}", svc.videoStatusHandler).Methods(http.MethodGet)
+	r.HandleFunc(baseUrl+"/api/video-events/{job_id}", svc.videoEventsHandler).Methods(http.MethodGet)
 	r.HandleFunc(baseUrl+"/api/validate-video", svc.validateVideoHandler).Methods(http.MethodPost)
 	r.HandleFunc(baseUrl+"/video/{filename}", svc.serveVideoHandler).Methods(http.MethodGet)
+	r.Handle(baseUrl+"/uploads/", svc.tusHandler).Methods(http.MethodPost, http.MethodOptions)
+	r.Handle(baseUrl+"/uploads/{id}", svc.tusHandler).Methods(http.MethodHead, http.MethodPatch, http.MethodOptions)
 	r.PathPrefix(baseUrl + "/static/").Handler(http.StripPrefix(baseUrl+"/static/", http.FileServer(http.Dir("./static/"))))
 	r.HandleFunc(baseUrl+"/robots.txt", func(w http.ResponseWriter, _ *http.Request) { fmt.Fprint(w, "User-agent: *\nDisallow: /") })
-	r.HandleFunc(baseUrl+"/_healthz", func(w http.ResponseWriter, _ *http.Request) { fmt.Fprint(w, "ok") })
+	r.HandleFunc(baseUrl+"/_healthz", svc.healthzHandler)
+	r.HandleFunc(baseUrl+"/_ready", svc.readyHandler)
+	r.Handle(baseUrl+"/metrics", promhttp.Handler()).Methods(http.MethodGet)
 	r.HandleFunc(baseUrl+"/product-meta/{ids}", svc.getProductByID).Methods(http.MethodGet)
-	r.HandleFunc(baseUrl+"/bot", svc.chatBotHandler).Methods(http.MethodPost)
-	r.HandleFunc(baseUrl+"/chat/stream", svc.chatStreamHandler).Methods(http.MethodPost)
+	r.HandleFunc(baseUrl+"/bot", svc.requireCSRF(svc.rateLimited(middleware.ClassChat, svc.chatBotHandler))).Methods(http.MethodPost)
+	r.HandleFunc(baseUrl+"/chat/stream", svc.requireCSRF(svc.rateLimited(middleware.ClassChat, svc.chatStreamHandler))).Methods(http.MethodPost)
 	r.HandleFunc(baseUrl+"/api/notifications", svc.getNotificationsHandler).Methods(http.MethodGet)
-	r.HandleFunc(baseUrl+"/api/notifications/{id}/read", svc.markNotificationReadHandler).Methods(http.MethodPost)
+	r.HandleFunc(baseUrl+"/api/notifications/stream", svc.requireCSRF(svc.notificationsStreamHandler)).Methods(http.MethodGet)
+	r.HandleFunc(baseUrl+"/api/notifications/{id}/read", svc.requireCSRF(svc.markNotificationReadHandler)).Methods(http.MethodPost)
+	r.HandleFunc(baseUrl+"/ads/reward", svc.adRewardHandler).Methods(http.MethodPost)
+	r.HandleFunc(baseUrl+"/debug/bandit", svc.debugBanditHandler).Methods(http.MethodGet)
+
+	// Admin moderation routes control whether a generated ad video goes live, so unlike
+	// rate-limiting/idempotency (which fail open when unconfigured), they fail closed: with no
+	// ADMIN_USERNAME/ADMIN_PASSWORD configured they simply aren't registered.
+	if credentials, ok := middleware.AdminCredentialsFromEnv(); ok {
+		adminAuth := middleware.BasicAuth(adminRealm(), credentials)
+		r.HandleFunc(baseUrl+"/admin/moderation", adminAuth(http.HandlerFunc(svc.adminModerationListHandler)).ServeHTTP).Methods(http.MethodGet)
+		r.HandleFunc(baseUrl+"/admin/moderation/{job_id}/decision", adminAuth(http.HandlerFunc(svc.adminModerationDecisionHandler)).ServeHTTP).Methods(http.MethodPost)
+	} else {
+		log.Warn("ADMIN_USERNAME/ADMIN_PASSWORD not set, admin moderation routes are disabled")
+	}
 
 	var handler http.Handler = r
-	handler = &logHandler{log: log, next: handler} // add logging
-	handler = ensureSessionID(handler)             // add session ID
+	handler = &logHandler{log: log, next: handler}              // add logging
+	handler = ensureSessionID(handler)                          // add session ID
+	handler = telemetry.WrapHandler(otelServiceName(), handler) // add OpenTelemetry tracing/metrics
+
+	srv := &http.Server{
+		Addr:    addr + ":" + srvPort,
+		Handler: handler,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
 
-	log.Infof("starting server on " + addr + ":" + srvPort)
-	log.Fatal(http.ListenAndServe(addr+":"+srvPort, handler))
-}
-func initStats(log logrus.FieldLogger) {
-	// TODO(arbrown) Implement stats
-}
+	g, gCtx := errgroup.WithContext(ctx)
 
-func initTracing(log logrus.FieldLogger) {
-	// Get Datadog Agent address from environment
-	agentHost := os.Getenv("DD_AGENT_HOST")
-	if agentHost == "" {
-		agentHost = "datadog-agent"
-	}
-	agentPort := os.Getenv("DD_TRACE_AGENT_PORT")
-	if agentPort == "" {
-		agentPort = "8126"
-	}
+	g.Go(func() error {
+		log.Infof("starting server on " + addr + ":" + srvPort)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			return errors.Wrap(err, "http server")
+		}
+		return nil
+	})
+
+	g.Go(func() error {
+		shutdownSig := make(chan os.Signal, 1)
+		signal.Notify(shutdownSig, syscall.SIGTERM, syscall.SIGINT)
+		select {
+		case sig := <-shutdownSig:
+			log.Infof("received signal %s, starting graceful shutdown", sig)
+		case <-gCtx.Done():
+			return nil
+		}
 
-	// Get service configuration
-	serviceName := os.Getenv("DD_SERVICE")
-	if serviceName == "" {
-		serviceName = "frontend"
-	}
-	serviceEnv := os.Getenv("DD_ENV")
-	if serviceEnv == "" {
-		serviceEnv = "hackathon"
+		// Flip readyHandler to 503 before anything else, so the load balancer stops sending new
+		// traffic here while the server below drains whatever's in flight (checkout submits, SSE
+		// notification streams, long-running PEAU chat streams).
+		svc.shuttingDown.Store(true)
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout())
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.WithError(err).Warn("failed to cleanly shut down HTTP server")
+		}
+
+		if err := svc.events.Close(); err != nil {
+			log.WithError(err).Warn("failed to cleanly close behavior event producer")
+		}
+		for name, conn := range map[string]*grpc.ClientConn{
+			"currency":        svc.currencySvcConn,
+			"product_catalog": svc.productCatalogSvcConn,
+			"cart":            svc.cartSvcConn,
+			"recommendation":  svc.recommendationSvcConn,
+			"shipping":        svc.shippingSvcConn,
+			"checkout":        svc.checkoutSvcConn,
+			"ad":              svc.adSvcConn,
+		} {
+			if conn == nil {
+				continue
+			}
+			if err := conn.Close(); err != nil {
+				log.WithError(err).WithField("backend", name).Warn("failed to cleanly close grpc backend connection")
+			}
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		log.WithError(err).Fatal("server error")
 	}
-	serviceVersion := os.Getenv("DD_VERSION")
-	if serviceVersion == "" {
-		serviceVersion = "1.0.0"
+}
+
+// shutdownTimeout bounds how long graceful shutdown waits for in-flight requests to finish before
+// srv.Shutdown gives up, overridable via SHUTDOWN_TIMEOUT (a duration, e.g. "1m").
+func shutdownTimeout() time.Duration {
+	if v, ok := resilience.DurationEnv("SHUTDOWN_TIMEOUT"); ok {
+		return v
 	}
+	return 30 * time.Second
+}
 
-	// Start the Datadog tracer
-	tracer.Start(
-		tracer.WithAgentAddr(fmt.Sprintf("%s:%s", agentHost, agentPort)),
-		tracer.WithService(serviceName),
-		tracer.WithEnv(serviceEnv),
-		tracer.WithServiceVersion(serviceVersion),
-		tracer.WithAnalytics(true),
-	)
+func initStats(log logrus.FieldLogger) {
+	// TODO(arbrown) Implement stats
+}
 
-	log.Infof("Datadog tracer initialized (agent: %s:%s, service: %s)", agentHost, agentPort, serviceName)
+// otelServiceName is this service's OpenTelemetry resource/span attribution, overridable via
+// OTEL_SERVICE_NAME (the standard OTel env var, so it lines up with whatever the rest of the
+// OTel ecosystem reads).
+func otelServiceName() string {
+	if v := os.Getenv("OTEL_SERVICE_NAME"); v != "" {
+		return v
+	}
+	return "frontend"
 }
 
 func initProfiling(log logrus.FieldLogger, service, version string) {
@@ -312,15 +667,180 @@ func mustMapEnv(target *string, envKey string) {
 	*target = v
 }
 
-func mustConnGRPC(ctx context.Context, conn **grpc.ClientConn, addr string) {
-	var err error
-	ctx, cancel := context.WithTimeout(ctx, time.Second*3)
-	defer cancel()
-	*conn, err = grpc.DialContext(ctx, addr,
-		grpc.WithInsecure(),
-		grpc.WithUnaryInterceptor(grpctrace.UnaryClientInterceptor()),
-		grpc.WithStreamInterceptor(grpctrace.StreamClientInterceptor()))
+// adBanditDecayInterval and adBanditDecayFactor configure how quickly the ad bandit's in-memory
+// posteriors are pulled back toward the flat Beta(1,1) prior (see bandit.MemoryStore), overridable
+// via AD_BANDIT_DECAY_INTERVAL (a duration, e.g. "1h") and AD_BANDIT_DECAY_FACTOR (a float in
+// (0,1]).
+func adBanditDecayInterval() time.Duration {
+	if v := os.Getenv("AD_BANDIT_DECAY_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return bandit.DefaultDecayInterval
+}
+
+func adBanditDecayFactor() float64 {
+	if v := os.Getenv("AD_BANDIT_DECAY_FACTOR"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return bandit.DefaultDecayFactor
+}
+
+// defaultIdempotencyTTL is how long placeOrderHandler's idempotency guard remembers a completed
+// order's response, overridable via IDEMPOTENCY_TTL (a duration, e.g. "24h").
+const defaultIdempotencyTTL = 24 * time.Hour
+
+func idempotencyTTL() time.Duration {
+	if v := os.Getenv("IDEMPOTENCY_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return defaultIdempotencyTTL
+}
+
+// defaultNotificationTTL matches cookieMaxAge: a notification outlives its session's cookie for
+// no good reason otherwise. Overridable via NOTIFICATION_TTL (a duration, e.g. "48h").
+const defaultNotificationTTL = cookieMaxAge * time.Second
+
+func notificationTTL() time.Duration {
+	if v := os.Getenv("NOTIFICATION_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return defaultNotificationTTL
+}
+
+// newNotificationStoreFromEnv builds the NotificationStore selected by NOTIFICATION_STORE
+// ("memory", the default, or "redis"). The redis backend additionally requires REDIS_ADDR and
+// starts its cross-replica pub/sub relay and background reaper on ctx.
+func newNotificationStoreFromEnv(ctx context.Context, log logrus.FieldLogger) (NotificationStore, error) {
+	switch store := os.Getenv("NOTIFICATION_STORE"); store {
+	case "", "memory":
+		return newMemoryStore(), nil
+	case "redis":
+		addr := os.Getenv("REDIS_ADDR")
+		if addr == "" {
+			return nil, errors.New("NOTIFICATION_STORE=redis requires REDIS_ADDR")
+		}
+		rs := newRedisStore(redis.NewClient(&redis.Options{Addr: addr}), notificationTTL())
+		go rs.relay(ctx, log)
+		go rs.StartReap(ctx, DefaultNotificationReapInterval, log)
+		return rs, nil
+	default:
+		return nil, errors.Errorf("unknown NOTIFICATION_STORE %q", store)
+	}
+}
+
+// videoCacheDir, videoFFmpegPath, videoCacheGCInterval, and videoCacheMaxBytes configure the
+// video package's on-disk rendition cache, overridable via VIDEO_CACHE_DIR, VIDEO_FFMPEG_PATH,
+// VIDEO_CACHE_GC_INTERVAL (a duration, e.g. "10m"), and VIDEO_CACHE_MAX_BYTES.
+func videoCacheDir() string {
+	if v := os.Getenv("VIDEO_CACHE_DIR"); v != "" {
+		return v
+	}
+	return filepath.Join(os.TempDir(), "video-cache")
+}
+
+func videoFFmpegPath() string {
+	if v := os.Getenv("VIDEO_FFMPEG_PATH"); v != "" {
+		return v
+	}
+	return "ffmpeg"
+}
+
+func videoCacheGCInterval() time.Duration {
+	if v := os.Getenv("VIDEO_CACHE_GC_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return video.DefaultGCInterval
+}
+
+func videoCacheMaxBytes() int64 {
+	if v := os.Getenv("VIDEO_CACHE_MAX_BYTES"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	}
+	return video.DefaultMaxCacheBytes
+}
+
+// newModerationStore builds the moderation.Store: a Postgres/SQLite-backed sqlStore when
+// MODERATION_DB_DSN is set (driver chosen by MODERATION_DB_DRIVER, "postgres" or "sqlite3"), else
+// an in-memory store so the admin moderation UI still works (non-persistent) in dev/test.
+func newModerationStore(ctx context.Context) (moderation.Store, error) {
+	dsn := os.Getenv("MODERATION_DB_DSN")
+	if dsn == "" {
+		return moderation.NewMemoryStore(), nil
+	}
+	driver := os.Getenv("MODERATION_DB_DRIVER")
+	if driver == "" {
+		driver = "postgres"
+	}
+	db, err := sql.Open(driver, dsn)
 	if err != nil {
-		panic(errors.Wrapf(err, "grpc: failed to connect %s", addr))
+		return nil, errors.Wrapf(err, "failed to open %s moderation database", driver)
+	}
+	return moderation.NewSQLStore(ctx, db, driver)
+}
+
+// adminRealm is the WWW-Authenticate realm admin routes challenge with, overridable via
+// ADMIN_REALM.
+func adminRealm() string {
+	if v := os.Getenv("ADMIN_REALM"); v != "" {
+		return v
 	}
+	return "v-commerce-studio admin"
+}
+
+// tusUploadDir, tusUploadExpiry, and tusMaxUploadBytes configure the tus package's resumable
+// upload store, overridable via TUS_UPLOAD_DIR, TUS_UPLOAD_EXPIRY (a duration, e.g. "24h"), and
+// TUS_MAX_UPLOAD_BYTES.
+func tusUploadDir() string {
+	if v := os.Getenv("TUS_UPLOAD_DIR"); v != "" {
+		return v
+	}
+	return filepath.Join(os.TempDir(), "tus-uploads")
+}
+
+func tusUploadExpiry() time.Duration {
+	if v := os.Getenv("TUS_UPLOAD_EXPIRY"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return tus.DefaultExpiry
+}
+
+func tusMaxUploadBytes() int64 {
+	if v := os.Getenv("TUS_MAX_UPLOAD_BYTES"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	}
+	return 0
+}
+
+// videoGenerateTimeout is generateVideoHandler's per-attempt timeout against the video-generation
+// service, longer than the other video-generation routes' since kicking off a generation job does
+// more upstream work than a status/search/validate lookup. Overridable via
+// VIDEO_GENERATION_SERVICE_GENERATE_TIMEOUT (a duration, e.g. "45s").
+func videoGenerateTimeout() time.Duration {
+	if v, ok := resilience.DurationEnv("VIDEO_GENERATION_SERVICE_GENERATE_TIMEOUT"); ok {
+		return v
+	}
+	return 30 * time.Second
+}
+
+// httpClientWithTimeout returns an *http.Client that shares fe.httpClient's pooled Transport (so
+// connections to the same host are reused across calls) but applies its own timeout, for call
+// sites that need a per-request deadline different from one another.
+func (fe *frontendServer) httpClientWithTimeout(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: fe.httpClient.Transport}
 }
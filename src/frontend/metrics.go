@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// httpRequestsTotal and httpRequestDuration are labeled by the matched mux route's path template
+// (e.g. "/product/{id}"), not the raw request path, so a templated route's cardinality stays
+// bounded regardless of how many distinct products/job IDs/etc. get requested.
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "frontend_http_requests_total",
+		Help: "HTTP requests served by the frontend, by route, method, and status code.",
+	}, []string{"route", "method", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "frontend_http_request_duration_seconds",
+		Help:    "HTTP request latency served by the frontend, by route and method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+)
+
+func init() {
+	prometheus.MustRegister(httpRequestsTotal, httpRequestDuration)
+}
+
+// routeMetricsMiddleware records httpRequestsTotal/httpRequestDuration for every request, keyed
+// by its matched route template. It must be registered via r.Use on the mux.Router itself (not
+// layered on as an outer http.Handler like logHandler) since mux.CurrentRoute only resolves once
+// the router has matched the request.
+func routeMetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		lrw := &loggingResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+		next.ServeHTTP(lrw, r)
+
+		route := routeTemplate(r)
+		httpRequestsTotal.WithLabelValues(route, r.Method, strconv.Itoa(lrw.statusCode)).Inc()
+		httpRequestDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+	})
+}
+
+// routeTemplate returns the matched route's path template, or "unmatched" for a 404/405 that
+// never matched any registered route.
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tmpl, err := route.GetPathTemplate(); err == nil {
+			return tmpl
+		}
+	}
+	return "unmatched"
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ctxKeyRequestID and ctxKeyLog are the context keys logHandler installs: a per-request ID (see
+// injectCommonTemplateData) and the logrus.FieldLogger every handler pulls via
+// r.Context().Value(ctxKeyLog{}) instead of calling the package-level logrus functions directly.
+type ctxKeyRequestID struct{}
+type ctxKeyLog struct{}
+
+// defaultSlowRequestThreshold is how long a request may take before logHandler promotes its log
+// entry to WARN and marks the active span as an error, overridable via SLOW_REQUEST_THRESHOLD (a
+// duration, e.g. "2s").
+const defaultSlowRequestThreshold = 1 * time.Second
+
+func slowRequestThreshold() time.Duration {
+	if v := os.Getenv("SLOW_REQUEST_THRESHOLD"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return defaultSlowRequestThreshold
+}
+
+// logHandler wraps every request with a structured, per-request logrus.FieldLogger correlated to
+// the active OpenTelemetry span (trace_id/span_id), so a log line and the trace/metrics it
+// happened under can be cross-referenced in whatever backend OTEL_EXPORTER points at. A request
+// slower than slowRequestThreshold is logged at WARN instead of DEBUG and has its span marked as
+// an error, so a trace backend's error-rate view surfaces latency problems even when the handler
+// itself returned 200.
+type logHandler struct {
+	log  *logrus.Logger
+	next http.Handler
+}
+
+func (lh *logHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	requestID := generateRequestID()
+	fields := logrus.Fields{
+		"http.req.path":   r.URL.Path,
+		"http.req.method": r.Method,
+		"http.req.id":     requestID,
+	}
+	spanCtx := trace.SpanContextFromContext(r.Context())
+	if spanCtx.HasTraceID() {
+		fields["trace_id"] = spanCtx.TraceID().String()
+	}
+	if spanCtx.HasSpanID() {
+		fields["span_id"] = spanCtx.SpanID().String()
+	}
+	requestLog := lh.log.WithFields(fields)
+
+	ctx := context.WithValue(r.Context(), ctxKeyRequestID{}, requestID)
+	ctx = context.WithValue(ctx, ctxKeyLog{}, requestLog)
+	r = r.WithContext(ctx)
+
+	lrw := &loggingResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+	lh.next.ServeHTTP(lrw, r)
+
+	duration := time.Since(start)
+	entry := requestLog.WithFields(logrus.Fields{
+		"http.resp.status_code": lrw.statusCode,
+		"http.resp.took_ms":     duration.Milliseconds(),
+	})
+
+	if duration >= slowRequestThreshold() {
+		entry.Warn("slow request")
+		if span := trace.SpanFromContext(r.Context()); span.IsRecording() {
+			span.SetStatus(codes.Error, "slow request")
+		}
+		return
+	}
+	entry.Debug("request completed")
+}
+
+// loggingResponseWriter captures the status code a handler wrote, defaulting to 200 if the
+// handler never calls WriteHeader (the same default net/http itself applies).
+type loggingResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (w *loggingResponseWriter) WriteHeader(code int) {
+	w.statusCode = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// ensureSessionID assigns every request a stable session ID: the cookieSessionID cookie's value
+// if present and its HMAC signature (see verifySessionID) valid, otherwise a freshly generated one
+// signed and set on the response for next time. Handlers read it via sessionID(r), which reads the
+// ctxKeySessionID this installs.
+func ensureSessionID(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := ""
+		if c, err := r.Cookie(cookieSessionID); err == nil {
+			if verified, ok := verifySessionID(c.Value); ok {
+				id = verified
+			}
+		}
+		if id == "" {
+			id = generateRequestID()
+			http.SetCookie(w, &http.Cookie{
+				Name:     cookieSessionID,
+				Value:    signSessionID(id),
+				MaxAge:   cookieMaxAge,
+				HttpOnly: true,
+				Secure:   isTLS(r),
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		ctx := context.WithValue(r.Context(), ctxKeySessionID{}, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
+
+// isTLS reports whether r arrived over TLS, directly or (behind a terminating load balancer, as
+// in this service's usual deployment) via the X-Forwarded-Proto header, so cookies only get the
+// Secure attribute when it won't make them unsendable.
+func isTLS(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+// generateRequestID mirrors generateIdempotencyKey's random-bytes-as-hex pattern (see
+// handlers.go) for the other opaque IDs this file hands out, without pulling in a UUID dependency.
+func generateRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBasicAuthAllowsMatchingCredentials(t *testing.T) {
+	h := BasicAuth("admin", map[string]string{"alice": "secret"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/admin/moderation", nil)
+	r.SetBasicAuth("alice", "secret")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+}
+
+func TestBasicAuthRejectsWrongPassword(t *testing.T) {
+	h := BasicAuth("admin", map[string]string{"alice": "secret"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not run for a bad password")
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/admin/moderation", nil)
+	r.SetBasicAuth("alice", "wrong")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+	if w.Header().Get("WWW-Authenticate") == "" {
+		t.Fatal("expected a WWW-Authenticate challenge header")
+	}
+}
+
+func TestBasicAuthRejectsMissingCredentials(t *testing.T) {
+	h := BasicAuth("admin", map[string]string{"alice": "secret"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not run with no credentials")
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/admin/moderation", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Route classes shared by frontend's rate-limited endpoints.
+const (
+	ClassChat          RouteClass = "chat"
+	ClassTryOn         RouteClass = "tryon"
+	ClassTrackBehavior RouteClass = "track_behavior"
+	ClassBrowse        RouteClass = "browse"
+)
+
+// defaultLimitsPerMinute mirrors the request's suggested defaults: chat 30/min, tryOn 5/min,
+// trackBehavior 120/min, browse 600/min.
+var defaultLimitsPerMinute = map[RouteClass]int64{
+	ClassChat:          30,
+	ClassTryOn:         5,
+	ClassTrackBehavior: 120,
+	ClassBrowse:        600,
+}
+
+// envVars maps each class to the env var that overrides its per-minute limit.
+var envVars = map[RouteClass]string{
+	ClassChat:          "CHAT_RATE_LIMIT_PER_MIN",
+	ClassTryOn:         "TRYON_RATE_LIMIT_PER_MIN",
+	ClassTrackBehavior: "TRACK_BEHAVIOR_RATE_LIMIT_PER_MIN",
+	ClassBrowse:        "BROWSE_RATE_LIMIT_PER_MIN",
+}
+
+// ClassesFromEnv builds the RouteClass -> window map RateLimiter needs, applying the env override
+// for each class (CHAT_RATE_LIMIT_PER_MIN, TRYON_RATE_LIMIT_PER_MIN,
+// TRACK_BEHAVIOR_RATE_LIMIT_PER_MIN, BROWSE_RATE_LIMIT_PER_MIN) over its default.
+func ClassesFromEnv() map[RouteClass]Window {
+	classes := make(map[RouteClass]Window, len(defaultLimitsPerMinute))
+	for class, def := range defaultLimitsPerMinute {
+		limit := def
+		if v, ok := intEnv(envVars[class]); ok {
+			limit = v
+		}
+		classes[class] = Window{Limit: limit, Period: time.Minute}
+	}
+	return classes
+}
+
+func intEnv(key string) (int64, bool) {
+	s := os.Getenv(key)
+	if s == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
@@ -0,0 +1,14 @@
+package middleware
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// rateLimitHits counts requests rejected by RateLimiter, labeled by route class and which scope
+// (session or ip) tripped the limit.
+var rateLimitHits = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "frontend_rate_limit_hits_total",
+	Help: "Requests rejected by the rate limiter, by route class and limiting scope.",
+}, []string{"class", "scope"})
+
+func init() {
+	prometheus.MustRegister(rateLimitHits)
+}
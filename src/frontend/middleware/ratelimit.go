@@ -0,0 +1,101 @@
+// Package middleware provides per-route HTTP middleware for the frontend: Redis-backed rate
+// limiting (this file) and a per-session concurrency cap for expensive downstream calls (see
+// semaphore.go).
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RouteClass groups routes that should share a rate-limit bucket (chat, tryOn, trackBehavior,
+// browse, ...). Each class has its own limit/window, configured via env vars (see config.go).
+type RouteClass string
+
+// Window is one class's sliding-window rate limit: at most Limit requests per Period.
+type Window struct {
+	Limit  int64
+	Period time.Duration
+}
+
+// store is the minimal interface RateLimiter needs from a backing counter. redisStore implements
+// it against Redis with a sorted-set sliding-window-log per key; tests use an in-memory fake so
+// the limiter's decision logic doesn't need a live Redis instance to exercise.
+type store interface {
+	// Count records one request for key at time now and returns the number of requests still
+	// within the trailing window duration (including this one).
+	Count(ctx context.Context, key string, now time.Time, window time.Duration) (int64, error)
+}
+
+// RateLimiter enforces a per-class sliding-window-log limit, checked independently against both
+// the caller's session ID and client IP so neither a shared IP (office NAT) nor a session-hopping
+// abuser can bypass it alone.
+type RateLimiter struct {
+	store   store
+	classes map[RouteClass]Window
+}
+
+// NewRateLimiter builds a RateLimiter backed by store, with one window per class in classes.
+func NewRateLimiter(store store, classes map[RouteClass]Window) *RateLimiter {
+	return &RateLimiter{store: store, classes: classes}
+}
+
+// Allow reports whether a request in class from the given session and IP should proceed. When it
+// returns false, retryAfter is how long the caller should wait before trying again.
+func (l *RateLimiter) Allow(ctx context.Context, class RouteClass, sessionID, ip string) (allowed bool, retryAfter time.Duration, err error) {
+	w, ok := l.classes[class]
+	if !ok || w.Limit <= 0 {
+		return true, 0, nil
+	}
+
+	now := time.Now()
+	for _, scope := range []struct {
+		name string
+		key  string
+	}{
+		{"session", fmt.Sprintf("ratelimit:%s:session:%s", class, sessionID)},
+		{"ip", fmt.Sprintf("ratelimit:%s:ip:%s", class, ip)},
+	} {
+		count, err := l.store.Count(ctx, scope.key, now, w.Period)
+		if err != nil {
+			return true, 0, err
+		}
+		if count > w.Limit {
+			rateLimitHits.WithLabelValues(string(class), scope.name).Inc()
+			return false, w.Period, nil
+		}
+	}
+	return true, 0, nil
+}
+
+// Middleware returns an http middleware enforcing class's limit, keyed by sessionIDFunc (the
+// frontend's sessionID cookie helper) and the request's remote IP. On a limit hit it writes a 429
+// with a Retry-After header and a small JSON error body instead of calling next.
+func (l *RateLimiter) Middleware(class RouteClass, sessionIDFunc func(*http.Request) string, clientIPFunc func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed, retryAfter, err := l.Allow(r.Context(), class, sessionIDFunc(r), clientIPFunc(r))
+			if err != nil {
+				// The rate limit store (Redis) is unreachable: fail open rather than taking every
+				// route in this class down because of a limiter outage.
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusTooManyRequests)
+				_ = json.NewEncoder(w).Encode(map[string]string{
+					"status":  http.StatusText(http.StatusTooManyRequests),
+					"message": "rate limit exceeded, please slow down",
+				})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
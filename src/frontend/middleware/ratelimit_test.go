@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeStore is an in-memory sliding-window-log, enough to exercise RateLimiter's decision logic
+// without a live Redis instance.
+type fakeStore struct {
+	mu    sync.Mutex
+	times map[string][]time.Time
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{times: make(map[string][]time.Time)}
+}
+
+func (s *fakeStore) Count(ctx context.Context, key string, now time.Time, w time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := now.Add(-w)
+	kept := s.times[key][:0]
+	for _, t := range s.times[key] {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	kept = append(kept, now)
+	s.times[key] = kept
+	return int64(len(kept)), nil
+}
+
+func TestRateLimiterAllowsUnderLimit(t *testing.T) {
+	l := NewRateLimiter(newFakeStore(), map[RouteClass]Window{ClassChat: {Limit: 3, Period: time.Minute}})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		allowed, _, err := l.Allow(ctx, ClassChat, "session-1", "1.2.3.4")
+		if err != nil {
+			t.Fatalf("Allow: %v", err)
+		}
+		if !allowed {
+			t.Fatalf("request %d should have been allowed under the limit", i)
+		}
+	}
+}
+
+func TestRateLimiterBlocksOverLimit(t *testing.T) {
+	l := NewRateLimiter(newFakeStore(), map[RouteClass]Window{ClassChat: {Limit: 2, Period: time.Minute}})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if allowed, _, err := l.Allow(ctx, ClassChat, "session-1", "1.2.3.4"); err != nil || !allowed {
+			t.Fatalf("request %d should have been allowed, allowed=%v err=%v", i, allowed, err)
+		}
+	}
+
+	allowed, retryAfter, err := l.Allow(ctx, ClassChat, "session-1", "1.2.3.4")
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	if allowed {
+		t.Fatal("expected the request over the limit to be blocked")
+	}
+	if retryAfter <= 0 {
+		t.Fatal("expected a positive Retry-After duration")
+	}
+}
+
+func TestRateLimiterTracksSessionAndIPIndependently(t *testing.T) {
+	l := NewRateLimiter(newFakeStore(), map[RouteClass]Window{ClassChat: {Limit: 1, Period: time.Minute}})
+	ctx := context.Background()
+
+	if allowed, _, err := l.Allow(ctx, ClassChat, "session-1", "1.2.3.4"); err != nil || !allowed {
+		t.Fatalf("first request should be allowed, allowed=%v err=%v", allowed, err)
+	}
+	// Same session from a different IP should still be blocked on the session bucket.
+	if allowed, _, _ := l.Allow(ctx, ClassChat, "session-1", "5.6.7.8"); allowed {
+		t.Fatal("expected the session bucket to block a repeat session regardless of IP")
+	}
+	// A different session from the first IP should still be blocked on the IP bucket.
+	if allowed, _, _ := l.Allow(ctx, ClassChat, "session-2", "1.2.3.4"); allowed {
+		t.Fatal("expected the IP bucket to block a repeat IP regardless of session")
+	}
+}
+
+func TestRateLimiterUnknownClassAlwaysAllows(t *testing.T) {
+	l := NewRateLimiter(newFakeStore(), map[RouteClass]Window{})
+	allowed, _, err := l.Allow(context.Background(), ClassChat, "session-1", "1.2.3.4")
+	if err != nil || !allowed {
+		t.Fatalf("expected an unconfigured class to pass through, allowed=%v err=%v", allowed, err)
+	}
+}
+
+func TestSessionSemaphoreCapsConcurrency(t *testing.T) {
+	sem := NewSessionSemaphore(2)
+
+	_, ok1 := sem.TryAcquire("session-1")
+	_, ok2 := sem.TryAcquire("session-1")
+	_, ok3 := sem.TryAcquire("session-1")
+	if !ok1 || !ok2 {
+		t.Fatal("expected the first two acquires to succeed")
+	}
+	if ok3 {
+		t.Fatal("expected the third acquire to be rejected once capacity is exhausted")
+	}
+}
+
+func TestSessionSemaphoreReleaseFreesASlot(t *testing.T) {
+	sem := NewSessionSemaphore(1)
+
+	release, ok := sem.TryAcquire("session-1")
+	if !ok {
+		t.Fatal("expected the first acquire to succeed")
+	}
+	if _, ok := sem.TryAcquire("session-1"); ok {
+		t.Fatal("expected a second acquire to fail while the slot is held")
+	}
+	release()
+	if _, ok := sem.TryAcquire("session-1"); !ok {
+		t.Fatal("expected an acquire to succeed again after release")
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisStore implements store as a sliding-window-log: each request appends its timestamp to a
+// per-key sorted set (score = unix nanos), trims anything older than window, and returns the
+// remaining cardinality. State lives in Redis rather than in-process, so the limit survives a
+// frontend restart and is shared across every replica hitting the same Redis.
+type redisStore struct {
+	client *redis.Client
+}
+
+// NewRedisRateLimiter builds a RateLimiter backed by a live Redis connection.
+func NewRedisRateLimiter(client *redis.Client, classes map[RouteClass]Window) *RateLimiter {
+	return NewRateLimiter(newRedisStore(client), classes)
+}
+
+func newRedisStore(client *redis.Client) *redisStore {
+	return &redisStore{client: client}
+}
+
+func (s *redisStore) Count(ctx context.Context, key string, now time.Time, w time.Duration) (int64, error) {
+	member := now.UnixNano()
+	minScore := now.Add(-w).UnixNano()
+
+	pipe := s.client.TxPipeline()
+	// The member must be unique per request independent of its score: ZADD treats the member as
+	// the entry's identity, so two requests whose UnixNano() lands on the same tick (plausible
+	// under the bursty traffic this limiter exists to catch) would otherwise collapse into one
+	// sorted-set entry and undercount the window.
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(member), Member: fmt.Sprintf("%d-%s", member, randomSuffix())})
+	pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(minScore, 10))
+	card := pipe.ZCard(ctx, key)
+	pipe.Expire(ctx, key, w)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, errors.Wrap(err, "middleware: failed to update rate limit window")
+	}
+	return card.Val(), nil
+}
+
+// randomSuffix mirrors generateRequestID's random-bytes-as-hex pattern (see middleware.go), used
+// here to disambiguate sorted-set members that share a score.
+func randomSuffix() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
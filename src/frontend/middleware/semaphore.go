@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"os"
+	"strconv"
+	"sync"
+)
+
+// DefaultTryOnMaxConcurrentPerSession caps how many try-on requests a single session can have in
+// flight at once, overridable via TRYON_MAX_CONCURRENT_PER_SESSION.
+const DefaultTryOnMaxConcurrentPerSession = 2
+
+// SessionSemaphore caps concurrent in-flight requests per session so one user can't exhaust a
+// shared, expensive downstream resource (GPU try-on workers) by firing many requests at once.
+// Each session gets its own bounded channel used as a semaphore, created lazily on first use.
+type SessionSemaphore struct {
+	mu       sync.Mutex
+	sessions map[string]chan struct{}
+	capacity int
+}
+
+// NewSessionSemaphore returns a SessionSemaphore allowing up to capacity concurrent requests per
+// session.
+func NewSessionSemaphore(capacity int) *SessionSemaphore {
+	return &SessionSemaphore{sessions: make(map[string]chan struct{}), capacity: capacity}
+}
+
+func (s *SessionSemaphore) sem(sessionID string) chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sem, ok := s.sessions[sessionID]
+	if !ok {
+		sem = make(chan struct{}, s.capacity)
+		s.sessions[sessionID] = sem
+	}
+	return sem
+}
+
+// TryAcquire attempts to reserve one of sessionID's slots. If it returns true, the caller must
+// call the returned release func exactly once when the request finishes.
+func (s *SessionSemaphore) TryAcquire(sessionID string) (release func(), ok bool) {
+	sem := s.sem(sessionID)
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, true
+	default:
+		return nil, false
+	}
+}
+
+// TryOnMaxConcurrentPerSessionFromEnv reads TRYON_MAX_CONCURRENT_PER_SESSION, falling back to
+// DefaultTryOnMaxConcurrentPerSession when unset or invalid.
+func TryOnMaxConcurrentPerSessionFromEnv() int {
+	if v := os.Getenv("TRYON_MAX_CONCURRENT_PER_SESSION"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return DefaultTryOnMaxConcurrentPerSession
+}
@@ -0,0 +1,105 @@
+package moderation
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// memoryStore is an in-memory Store, used by tests in place of a live Postgres/SQLite instance and
+// by the frontend when MODERATION_DB_DSN is unset.
+type memoryStore struct {
+	mu    sync.Mutex
+	items map[string]*Item
+	log   map[string][]Decision
+}
+
+// NewMemoryStore builds an in-memory Store.
+func NewMemoryStore() Store {
+	return &memoryStore{items: make(map[string]*Item), log: make(map[string][]Decision)}
+}
+
+func (s *memoryStore) Submit(ctx context.Context, jobID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.items[jobID]; ok {
+		return nil
+	}
+	now := time.Now()
+	s.items[jobID] = &Item{JobID: jobID, Status: StatusPending, SubmittedAt: now, UpdatedAt: now}
+	return nil
+}
+
+func (s *memoryStore) List(ctx context.Context, filter ListFilter) (ListResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matched []Item
+	for _, item := range s.items {
+		if filter.Status != "" && item.Status != filter.Status {
+			continue
+		}
+		if filter.Reviewer != "" && item.Reviewer != filter.Reviewer {
+			continue
+		}
+		if !filter.Since.IsZero() && item.SubmittedAt.Before(filter.Since) {
+			continue
+		}
+		if !filter.Until.IsZero() && item.SubmittedAt.After(filter.Until) {
+			continue
+		}
+		matched = append(matched, *item)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].SubmittedAt.Before(matched[j].SubmittedAt) })
+
+	page, perPage := filter.normalized()
+	start := (page - 1) * perPage
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := start + perPage
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	return ListResult{Items: matched[start:end], Page: page, PerPage: perPage, TotalItems: len(matched)}, nil
+}
+
+func (s *memoryStore) Get(ctx context.Context, jobID string) (*Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.items[jobID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *item
+	return &copied, nil
+}
+
+func (s *memoryStore) RecordDecision(ctx context.Context, decision Decision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.log[decision.JobID] = append(s.log[decision.JobID], decision)
+
+	item, ok := s.items[decision.JobID]
+	if !ok {
+		item = &Item{JobID: decision.JobID, SubmittedAt: decision.OccurredAt}
+		s.items[decision.JobID] = item
+	}
+	item.Status = decision.Outcome.ToStatus()
+	item.Reason = decision.Reason
+	item.Reviewer = decision.Reviewer
+	item.UpdatedAt = decision.OccurredAt
+	return nil
+}
+
+func (s *memoryStore) AuditLog(ctx context.Context, jobID string) ([]Decision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	log := s.log[jobID]
+	result := make([]Decision, len(log))
+	copy(result, log)
+	return result, nil
+}
@@ -0,0 +1,127 @@
+// Package moderation tracks the review state of generated ad videos: a queue of items awaiting a
+// human decision, and an immutable audit log of every decision ever made. See sql_store.go for
+// the Postgres/SQLite-backed Store and memory_store.go for the in-memory Store used by tests and
+// when MODERATION_DB_DSN is unset.
+package moderation
+
+import (
+	"context"
+	"time"
+)
+
+// Status is a moderation item's current review state.
+type Status string
+
+const (
+	StatusPending          Status = "pending"
+	StatusApproved         Status = "approved"
+	StatusRejected         Status = "rejected"
+	StatusChangesRequested Status = "changes_requested"
+)
+
+// Outcome is the decision a reviewer records. It's a narrower set than Status: every Outcome maps
+// onto a Status, but Status also includes StatusPending, which has no corresponding Outcome.
+type Outcome string
+
+const (
+	OutcomeApprove        Outcome = "approve"
+	OutcomeReject         Outcome = "reject"
+	OutcomeRequestChanges Outcome = "request_changes"
+)
+
+// ToStatus maps a reviewer's Outcome onto the Status it leaves the item in.
+func (o Outcome) ToStatus() Status {
+	switch o {
+	case OutcomeApprove:
+		return StatusApproved
+	case OutcomeReject:
+		return StatusRejected
+	case OutcomeRequestChanges:
+		return StatusChangesRequested
+	default:
+		return StatusPending
+	}
+}
+
+// Valid reports whether o is one of the known decision outcomes.
+func (o Outcome) Valid() bool {
+	switch o {
+	case OutcomeApprove, OutcomeReject, OutcomeRequestChanges:
+		return true
+	default:
+		return false
+	}
+}
+
+// Item is one job's current moderation state.
+type Item struct {
+	JobID       string    `json:"job_id"`
+	Status      Status    `json:"status"`
+	Reason      string    `json:"reason,omitempty"`
+	Reviewer    string    `json:"reviewer,omitempty"`
+	SubmittedAt time.Time `json:"submitted_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Decision is one append-only audit log entry: a single reviewer action on a single job.
+type Decision struct {
+	JobID        string    `json:"job_id"`
+	Outcome      Outcome   `json:"outcome"`
+	Reason       string    `json:"reason,omitempty"`
+	PolicyLabels []string  `json:"policy_labels,omitempty"`
+	Reviewer     string    `json:"reviewer"`
+	ClientIP     string    `json:"client_ip"`
+	ContentHash  string    `json:"content_hash,omitempty"`
+	OccurredAt   time.Time `json:"occurred_at"`
+}
+
+// ListFilter narrows GET /admin/moderation's queue listing.
+type ListFilter struct {
+	Status   Status // zero value: no status filter
+	Reviewer string // zero value: no reviewer filter
+	Since    time.Time
+	Until    time.Time
+	Page     int // 1-indexed; zero/negative treated as 1
+	PerPage  int // zero/negative treated as DefaultPerPage
+}
+
+// DefaultPerPage bounds ListFilter.PerPage when unset.
+const DefaultPerPage = 25
+
+// normalized normalizes f's pagination fields, returning 1-indexed page and a positive perPage.
+func (f ListFilter) normalized() (page, perPage int) {
+	page = f.Page
+	if page < 1 {
+		page = 1
+	}
+	perPage = f.PerPage
+	if perPage < 1 {
+		perPage = DefaultPerPage
+	}
+	return page, perPage
+}
+
+// ListResult is one page of a queue listing.
+type ListResult struct {
+	Items      []Item `json:"items"`
+	Page       int    `json:"page"`
+	PerPage    int    `json:"per_page"`
+	TotalItems int    `json:"total_items"`
+}
+
+// Store is the persistence boundary for the moderation queue and its audit log. sqlStore
+// implements it against Postgres or SQLite (any database/sql driver); tests, and the frontend when
+// MODERATION_DB_DSN is unset, use the in-memory memoryStore.
+type Store interface {
+	// Submit enqueues jobID for review if it isn't already tracked. Submitting an already-known
+	// job is a no-op: it doesn't reset a prior decision.
+	Submit(ctx context.Context, jobID string) error
+	// List returns one page of the queue matching filter.
+	List(ctx context.Context, filter ListFilter) (ListResult, error)
+	// Get returns jobID's current item, or nil, nil if it isn't tracked.
+	Get(ctx context.Context, jobID string) (*Item, error)
+	// RecordDecision appends decision to the audit log and updates jobID's queue item to match.
+	RecordDecision(ctx context.Context, decision Decision) error
+	// AuditLog returns every decision ever recorded for jobID, oldest first.
+	AuditLog(ctx context.Context, jobID string) ([]Decision, error)
+}
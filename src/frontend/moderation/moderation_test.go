@@ -0,0 +1,144 @@
+package moderation
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreSubmitIsIdempotent(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+
+	if err := s.Submit(ctx, "job-1"); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if err := s.Submit(ctx, "job-1"); err != nil {
+		t.Fatalf("Submit (repeat): %v", err)
+	}
+
+	result, err := s.List(ctx, ListFilter{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if result.TotalItems != 1 {
+		t.Fatalf("expected exactly one queue item after two Submits, got %d", result.TotalItems)
+	}
+	if result.Items[0].Status != StatusPending {
+		t.Fatalf("expected a freshly submitted item to be pending, got %s", result.Items[0].Status)
+	}
+}
+
+func TestRecordDecisionUpdatesItemAndAppendsAuditLog(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+	if err := s.Submit(ctx, "job-1"); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	decision := Decision{
+		JobID:        "job-1",
+		Outcome:      OutcomeReject,
+		Reason:       "contains disallowed claim",
+		PolicyLabels: []string{"misleading-claim"},
+		Reviewer:     "alice",
+		ClientIP:     "10.0.0.1",
+		ContentHash:  "deadbeef",
+		OccurredAt:   time.Now(),
+	}
+	if err := s.RecordDecision(ctx, decision); err != nil {
+		t.Fatalf("RecordDecision: %v", err)
+	}
+
+	item, err := s.Get(ctx, "job-1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if item.Status != StatusRejected {
+		t.Fatalf("expected item status %s, got %s", StatusRejected, item.Status)
+	}
+	if item.Reason != decision.Reason {
+		t.Fatalf("expected item reason %q, got %q", decision.Reason, item.Reason)
+	}
+
+	log, err := s.AuditLog(ctx, "job-1")
+	if err != nil {
+		t.Fatalf("AuditLog: %v", err)
+	}
+	if len(log) != 1 {
+		t.Fatalf("expected exactly one audit log entry, got %d", len(log))
+	}
+	if log[0].Reviewer != "alice" || log[0].ContentHash != "deadbeef" {
+		t.Fatalf("unexpected audit log entry %+v", log[0])
+	}
+
+	// A second decision on the same job appends to, rather than replaces, the audit log.
+	second := decision
+	second.Outcome = OutcomeApprove
+	second.Reason = "resubmission addressed the concern"
+	if err := s.RecordDecision(ctx, second); err != nil {
+		t.Fatalf("RecordDecision (second): %v", err)
+	}
+	log, err = s.AuditLog(ctx, "job-1")
+	if err != nil {
+		t.Fatalf("AuditLog: %v", err)
+	}
+	if len(log) != 2 {
+		t.Fatalf("expected two audit log entries after a second decision, got %d", len(log))
+	}
+
+	item, _ = s.Get(ctx, "job-1")
+	if item.Status != StatusApproved {
+		t.Fatalf("expected the latest decision to win, got status %s", item.Status)
+	}
+}
+
+func TestListFiltersByStatus(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+	for _, jobID := range []string{"job-1", "job-2", "job-3"} {
+		if err := s.Submit(ctx, jobID); err != nil {
+			t.Fatalf("Submit: %v", err)
+		}
+	}
+	if err := s.RecordDecision(ctx, Decision{JobID: "job-2", Outcome: OutcomeApprove, Reviewer: "bob", OccurredAt: time.Now()}); err != nil {
+		t.Fatalf("RecordDecision: %v", err)
+	}
+
+	result, err := s.List(ctx, ListFilter{Status: StatusPending})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if result.TotalItems != 2 {
+		t.Fatalf("expected 2 pending items, got %d", result.TotalItems)
+	}
+
+	result, err = s.List(ctx, ListFilter{Status: StatusApproved})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if result.TotalItems != 1 || result.Items[0].JobID != "job-2" {
+		t.Fatalf("expected exactly job-2 to be approved, got %+v", result.Items)
+	}
+}
+
+func TestListPaginates(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		if err := s.Submit(ctx, string(rune('a'+i))); err != nil {
+			t.Fatalf("Submit: %v", err)
+		}
+	}
+
+	result, err := s.List(ctx, ListFilter{Page: 2, PerPage: 2})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if result.TotalItems != 5 {
+		t.Fatalf("expected TotalItems to count the whole set, got %d", result.TotalItems)
+	}
+	if len(result.Items) != 2 {
+		t.Fatalf("expected page 2 to have 2 items, got %d", len(result.Items))
+	}
+}
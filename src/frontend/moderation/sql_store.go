@@ -0,0 +1,250 @@
+package moderation
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// sqlStore implements Store against any database/sql driver; Postgres and SQLite are both
+// supported (MODERATION_DB_DRIVER selects which), since neither the queue nor the audit log use
+// anything beyond portable SQL.
+type sqlStore struct {
+	db     *sql.DB
+	driver string
+}
+
+// NewSQLStore builds a Store backed by db, using driver ("postgres" or "sqlite3") only to decide
+// placeholder syntax ("$1" vs "?"). The caller owns opening db (via sql.Open(driver, dsn)) and
+// its connection pool settings.
+func NewSQLStore(ctx context.Context, db *sql.DB, driver string) (Store, error) {
+	s := &sqlStore{db: db, driver: driver}
+	if err := s.migrate(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *sqlStore) migrate(ctx context.Context) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS moderation_items (
+			job_id       TEXT PRIMARY KEY,
+			status       TEXT NOT NULL,
+			reason       TEXT NOT NULL DEFAULT '',
+			reviewer     TEXT NOT NULL DEFAULT '',
+			submitted_at TIMESTAMP NOT NULL,
+			updated_at   TIMESTAMP NOT NULL
+		)`,
+		// Append-only: rows are never updated or deleted by this package.
+		`CREATE TABLE IF NOT EXISTS moderation_audit_log (
+			job_id        TEXT NOT NULL,
+			outcome       TEXT NOT NULL,
+			reason        TEXT NOT NULL DEFAULT '',
+			policy_labels TEXT NOT NULL DEFAULT '',
+			reviewer      TEXT NOT NULL,
+			client_ip     TEXT NOT NULL DEFAULT '',
+			content_hash  TEXT NOT NULL DEFAULT '',
+			occurred_at   TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS moderation_audit_log_job_id_idx ON moderation_audit_log (job_id)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return errors.Wrap(err, "moderation: failed to migrate schema")
+		}
+	}
+	return nil
+}
+
+// rebind rewrites a query written with "?" placeholders into driver's syntax ("$1", "$2", ... for
+// postgres; "?" is already correct for sqlite3).
+func rebind(driver, query string) string {
+	if driver != "postgres" {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (s *sqlStore) rebind(query string) string {
+	return rebind(s.driver, query)
+}
+
+func (s *sqlStore) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return s.db.ExecContext(ctx, s.rebind(query), args...)
+}
+
+func (s *sqlStore) query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, s.rebind(query), args...)
+}
+
+func (s *sqlStore) queryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return s.db.QueryRowContext(ctx, s.rebind(query), args...)
+}
+
+func (s *sqlStore) Submit(ctx context.Context, jobID string) error {
+	now := time.Now()
+	_, err := s.exec(ctx, `
+		INSERT INTO moderation_items (job_id, status, submitted_at, updated_at)
+		SELECT ?, ?, ?, ?
+		WHERE NOT EXISTS (SELECT 1 FROM moderation_items WHERE job_id = ?)`,
+		jobID, StatusPending, now, now, jobID)
+	if err != nil {
+		return errors.Wrap(err, "moderation: failed to submit job")
+	}
+	return nil
+}
+
+func (s *sqlStore) List(ctx context.Context, filter ListFilter) (ListResult, error) {
+	page, perPage := filter.normalized()
+
+	var where []string
+	var args []interface{}
+	if filter.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.Reviewer != "" {
+		where = append(where, "reviewer = ?")
+		args = append(args, filter.Reviewer)
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, "submitted_at >= ?")
+		args = append(args, filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, "submitted_at <= ?")
+		args = append(args, filter.Until)
+	}
+	whereClause := ""
+	if len(where) > 0 {
+		whereClause = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM moderation_items %s`, whereClause)
+	if err := s.queryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return ListResult{}, errors.Wrap(err, "moderation: failed to count queue")
+	}
+
+	listQuery := fmt.Sprintf(`
+		SELECT job_id, status, reason, reviewer, submitted_at, updated_at
+		FROM moderation_items %s
+		ORDER BY submitted_at ASC
+		LIMIT ? OFFSET ?`, whereClause)
+	rows, err := s.query(ctx, listQuery, append(args, perPage, (page-1)*perPage)...)
+	if err != nil {
+		return ListResult{}, errors.Wrap(err, "moderation: failed to list queue")
+	}
+	defer rows.Close()
+
+	var items []Item
+	for rows.Next() {
+		var item Item
+		if err := rows.Scan(&item.JobID, &item.Status, &item.Reason, &item.Reviewer, &item.SubmittedAt, &item.UpdatedAt); err != nil {
+			return ListResult{}, errors.Wrap(err, "moderation: failed to scan queue item")
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return ListResult{}, errors.Wrap(err, "moderation: failed to read queue")
+	}
+
+	return ListResult{Items: items, Page: page, PerPage: perPage, TotalItems: total}, nil
+}
+
+func (s *sqlStore) Get(ctx context.Context, jobID string) (*Item, error) {
+	var item Item
+	err := s.queryRow(ctx, `
+		SELECT job_id, status, reason, reviewer, submitted_at, updated_at
+		FROM moderation_items WHERE job_id = ?`, jobID,
+	).Scan(&item.JobID, &item.Status, &item.Reason, &item.Reviewer, &item.SubmittedAt, &item.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "moderation: failed to get item")
+	}
+	return &item, nil
+}
+
+func (s *sqlStore) RecordDecision(ctx context.Context, decision Decision) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "moderation: failed to begin transaction")
+	}
+	defer tx.Rollback() //nolint:errcheck // no-op once committed
+
+	_, err = tx.ExecContext(ctx, s.rebind(`
+		INSERT INTO moderation_audit_log
+			(job_id, outcome, reason, policy_labels, reviewer, client_ip, content_hash, occurred_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`),
+		decision.JobID, decision.Outcome, decision.Reason, strings.Join(decision.PolicyLabels, ","),
+		decision.Reviewer, decision.ClientIP, decision.ContentHash, decision.OccurredAt)
+	if err != nil {
+		return errors.Wrap(err, "moderation: failed to append audit log entry")
+	}
+
+	res, err := tx.ExecContext(ctx, s.rebind(`
+		UPDATE moderation_items SET status = ?, reason = ?, reviewer = ?, updated_at = ?
+		WHERE job_id = ?`),
+		decision.Outcome.ToStatus(), decision.Reason, decision.Reviewer, decision.OccurredAt, decision.JobID)
+	if err != nil {
+		return errors.Wrap(err, "moderation: failed to update queue item")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		_, err = tx.ExecContext(ctx, s.rebind(`
+			INSERT INTO moderation_items (job_id, status, reason, reviewer, submitted_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)`),
+			decision.JobID, decision.Outcome.ToStatus(), decision.Reason, decision.Reviewer, decision.OccurredAt, decision.OccurredAt)
+		if err != nil {
+			return errors.Wrap(err, "moderation: failed to insert queue item for decision")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "moderation: failed to commit decision")
+	}
+	return nil
+}
+
+func (s *sqlStore) AuditLog(ctx context.Context, jobID string) ([]Decision, error) {
+	rows, err := s.query(ctx, `
+		SELECT job_id, outcome, reason, policy_labels, reviewer, client_ip, content_hash, occurred_at
+		FROM moderation_audit_log WHERE job_id = ? ORDER BY occurred_at ASC`, jobID)
+	if err != nil {
+		return nil, errors.Wrap(err, "moderation: failed to read audit log")
+	}
+	defer rows.Close()
+
+	var log []Decision
+	for rows.Next() {
+		var d Decision
+		var policyLabels string
+		if err := rows.Scan(&d.JobID, &d.Outcome, &d.Reason, &policyLabels, &d.Reviewer, &d.ClientIP, &d.ContentHash, &d.OccurredAt); err != nil {
+			return nil, errors.Wrap(err, "moderation: failed to scan audit log entry")
+		}
+		if policyLabels != "" {
+			d.PolicyLabels = strings.Split(policyLabels, ",")
+		}
+		log = append(log, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "moderation: failed to read audit log")
+	}
+	return log, nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultNotificationReapInterval is how often redisStore's reaper sweeps for entries older than
+// the store's TTL.
+const DefaultNotificationReapInterval = 10 * time.Minute
+
+// redisStore is the persistent, multi-replica NotificationStore implementation: notification
+// history lives in a Redis sorted set per session (score = the notification's unix-nano
+// timestamp, for cheap chronological listing and trimming), and every AddNotification publishes
+// to a per-session pub/sub channel so each replica's localFanout (including the one that served
+// the request) observes the same stream instead of only the replica that happened to persist it.
+type redisStore struct {
+	*localFanout
+
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// newRedisStore builds a NotificationStore backed by a live Redis connection. Callers must also
+// start relay (to receive pushes) and, typically, StartReap (to bound session history) on their
+// own goroutines; newNotificationStoreFromEnv does both.
+func newRedisStore(client *redis.Client, ttl time.Duration) *redisStore {
+	return &redisStore{localFanout: newLocalFanout(), client: client, ttl: ttl}
+}
+
+func (rs *redisStore) AddNotification(ctx context.Context, sessionID, userID, message string) {
+	now := time.Now()
+	raw, err := json.Marshal(&Notification{
+		ID:        sessionID + "_" + strconv.FormatInt(now.UnixNano(), 10),
+		Message:   message,
+		Timestamp: now,
+		UserID:    userID,
+		Read:      false,
+	})
+	if err != nil {
+		return
+	}
+
+	pipe := rs.client.TxPipeline()
+	pipe.ZAdd(ctx, notificationKey(sessionID), redis.Z{Score: float64(now.UnixNano()), Member: raw})
+	pipe.Expire(ctx, notificationKey(sessionID), rs.ttl)
+	pipe.Publish(ctx, notificationChannel(sessionID), raw)
+	_, _ = pipe.Exec(ctx)
+}
+
+func (rs *redisStore) GetNotifications(ctx context.Context, sessionID string) []*Notification {
+	members, err := rs.client.ZRange(ctx, notificationKey(sessionID), 0, -1).Result()
+	if err != nil {
+		return []*Notification{}
+	}
+
+	notifications := make([]*Notification, 0, len(members))
+	for _, member := range members {
+		var n Notification
+		if err := json.Unmarshal([]byte(member), &n); err != nil {
+			continue
+		}
+		notifications = append(notifications, &n)
+	}
+	return notifications
+}
+
+// markAsReadScript finds the sorted-set member with the given notification ID and replaces it
+// with a copy that has read set, preserving its original score. A plain Go ZREM-then-ZADD would
+// race with a concurrent AddNotification's ZADD landing on the same key in between.
+const markAsReadScript = `
+local members = redis.call("ZRANGE", KEYS[1], 0, -1, "WITHSCORES")
+for i = 1, #members, 2 do
+	local member, score = members[i], members[i + 1]
+	local decoded = cjson.decode(member)
+	if decoded.id == ARGV[1] then
+		decoded.read = true
+		redis.call("ZREM", KEYS[1], member)
+		redis.call("ZADD", KEYS[1], score, cjson.encode(decoded))
+		return 1
+	end
+end
+return 0
+`
+
+func (rs *redisStore) MarkAsRead(ctx context.Context, sessionID, notificationID string) {
+	_ = rs.client.Eval(ctx, markAsReadScript, []string{notificationKey(sessionID)}, notificationID).Err()
+}
+
+// relay subscribes to every session's pub/sub channel and delivers what it receives to this
+// replica's localFanout, until ctx is canceled. It's what makes AddNotification's Publish visible
+// to live Subscribe callers on every replica, not just the one that persisted the notification.
+func (rs *redisStore) relay(ctx context.Context, log logrus.FieldLogger) {
+	pubsub := rs.client.PSubscribe(ctx, notificationChannel("*"))
+	defer pubsub.Close()
+
+	for {
+		select {
+		case msg, ok := <-pubsub.Channel():
+			if !ok {
+				return
+			}
+			var n Notification
+			if err := json.Unmarshal([]byte(msg.Payload), &n); err != nil {
+				log.WithError(err).Warn("notifications: failed to decode relayed notification")
+				continue
+			}
+			rs.deliver(sessionIDFromChannel(msg.Channel), &n)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RunReap trims every session's sorted set down to entries no older than rs.ttl. A session that
+// keeps receiving notifications keeps refreshing its key's EXPIRE, so without this, entries older
+// than ttl would otherwise accumulate for as long as the session stays active.
+func (rs *redisStore) RunReap(ctx context.Context, log logrus.FieldLogger) {
+	cutoff := strconv.FormatInt(time.Now().Add(-rs.ttl).UnixNano(), 10)
+
+	var cursor uint64
+	for {
+		keys, next, err := rs.client.Scan(ctx, cursor, notificationKeyPrefix+"*", 100).Result()
+		if err != nil {
+			log.WithError(err).Warn("notifications: reaper scan failed")
+			return
+		}
+		for _, key := range keys {
+			if err := rs.client.ZRemRangeByScore(ctx, key, "-inf", cutoff).Err(); err != nil {
+				log.WithError(err).WithField("key", key).Warn("notifications: reaper trim failed")
+			}
+		}
+		cursor = next
+		if cursor == 0 {
+			return
+		}
+	}
+}
+
+// StartReap runs RunReap on a ticker until ctx is canceled.
+func (rs *redisStore) StartReap(ctx context.Context, interval time.Duration, log logrus.FieldLogger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rs.RunReap(ctx, log)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+const notificationKeyPrefix = "notifications:"
+
+func notificationKey(sessionID string) string {
+	return notificationKeyPrefix + sessionID
+}
+
+func notificationChannel(sessionID string) string {
+	return notificationKeyPrefix + sessionID + ":stream"
+}
+
+func sessionIDFromChannel(channel string) string {
+	channel = channel[len(notificationKeyPrefix):]
+	return channel[:len(channel)-len(":stream")]
+}
@@ -0,0 +1,222 @@
+// Package packaging talks to the packaging microservice over a gRPC bidirectional stream,
+// falling back to the legacy HTTP lookup when no gRPC endpoint is configured.
+package packaging
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/frontend/genproto/packaging"
+	grpctrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/google.golang.org/grpc"
+)
+
+const (
+	envGRPCAddr = "PACKAGING_SERVICE_GRPC_URL"
+	cacheTTL    = 5 * time.Minute
+)
+
+// Info mirrors pb.PackagingInfo so callers don't need to depend on the proto package directly.
+type Info struct {
+	Weight float32
+	Width  float32
+	Height float32
+	Depth  float32
+}
+
+// HTTPFallback performs the legacy one-request-per-product HTTP lookup. It's injected rather than
+// imported so this package has no dependency on the rest of the frontend.
+type HTTPFallback func(productID string) (*Info, error)
+
+type cacheEntry struct {
+	info    *Info
+	expires time.Time
+}
+
+// Client is a request-scoped-friendly wrapper around the packaging gRPC stream: every call opens
+// (or reuses, via Batch) a single bidirectional stream, multiplexes product ID lookups over it,
+// and resolves responses out of order by product ID. Results are cached in-memory with a TTL, and
+// concurrent lookups for the same product are collapsed with singleflight.
+type Client struct {
+	conn     *grpc.ClientConn
+	fallback HTTPFallback
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+
+	group singleflight.Group
+}
+
+// NewClient dials the packaging gRPC service when PACKAGING_SERVICE_GRPC_URL is set. If it isn't,
+// the returned client is still usable: every lookup goes straight to fallback.
+func NewClient(fallback HTTPFallback) (*Client, error) {
+	c := &Client{
+		cache:    make(map[string]cacheEntry),
+		fallback: fallback,
+	}
+
+	addr := os.Getenv(envGRPCAddr)
+	if addr == "" {
+		return c, nil
+	}
+
+	conn, err := grpc.Dial(addr,
+		grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(grpctrace.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(grpctrace.StreamClientInterceptor()))
+	if err != nil {
+		return nil, errors.Wrapf(err, "packaging: failed to dial %s", addr)
+	}
+	c.conn = conn
+	return c, nil
+}
+
+// Close releases the underlying gRPC connection, if one was opened.
+func (c *Client) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// Get resolves packaging info for a single product, checking the cache first and collapsing
+// concurrent callers for the same product ID into one lookup.
+func (c *Client) Get(ctx context.Context, productID string) (*Info, error) {
+	if info, ok := c.fromCache(productID); ok {
+		return info, nil
+	}
+
+	v, err, _ := c.group.Do(productID, func() (interface{}, error) {
+		results, err := c.GetPackagingInfoBatch(ctx, []string{productID})
+		if err != nil {
+			return nil, err
+		}
+		info, ok := results[productID]
+		if !ok {
+			return nil, errors.Errorf("packaging: no info returned for product %s", productID)
+		}
+		return info, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Info), nil
+}
+
+// GetPackagingInfoBatch opens a single bidirectional stream (or falls back to one HTTP call per
+// miss), pushes every product ID that isn't already cached, and returns whatever the stream or
+// fallback resolved, keyed by product ID. A missing entry means that product's lookup failed.
+func (c *Client) GetPackagingInfoBatch(ctx context.Context, productIDs []string) (map[string]*Info, error) {
+	out := make(map[string]*Info, len(productIDs))
+	var misses []string
+	for _, id := range productIDs {
+		if info, ok := c.fromCache(id); ok {
+			out[id] = info
+			continue
+		}
+		misses = append(misses, id)
+	}
+	if len(misses) == 0 {
+		return out, nil
+	}
+
+	var (
+		results map[string]*Info
+		err     error
+	)
+	if c.conn != nil {
+		results, err = c.streamBatch(ctx, misses)
+		if err != nil {
+			results = c.fallbackBatch(misses)
+		}
+	} else {
+		results = c.fallbackBatch(misses)
+	}
+
+	for id, info := range results {
+		c.store(id, info)
+		out[id] = info
+	}
+	return out, nil
+}
+
+func (c *Client) streamBatch(ctx context.Context, productIDs []string) (map[string]*Info, error) {
+	stream, err := pb.NewPackagingServiceClient(c.conn).StreamPackagingInfo(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "packaging: failed to open stream")
+	}
+
+	pending := make(map[string]struct{}, len(productIDs))
+	for _, id := range productIDs {
+		pending[id] = struct{}{}
+	}
+
+	go func() {
+		for _, id := range productIDs {
+			if sendErr := stream.Send(&pb.PackagingInfoRequest{ProductId: id}); sendErr != nil {
+				return
+			}
+		}
+		stream.CloseSend()
+	}()
+
+	results := make(map[string]*Info, len(productIDs))
+	for len(pending) > 0 {
+		resp, err := stream.Recv()
+		if err != nil {
+			return results, err
+		}
+		id := resp.GetProductId()
+		results[id] = &Info{
+			Weight: resp.GetWeight(),
+			Width:  resp.GetWidth(),
+			Height: resp.GetHeight(),
+			Depth:  resp.GetDepth(),
+		}
+		delete(pending, id)
+	}
+	return results, nil
+}
+
+func (c *Client) fallbackBatch(productIDs []string) map[string]*Info {
+	results := make(map[string]*Info, len(productIDs))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, id := range productIDs {
+		id := id
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			info, err := c.fallback(id)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			results[id] = info
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func (c *Client) fromCache(productID string) (*Info, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.cache[productID]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.info, true
+}
+
+func (c *Client) store(productID string, info *Info) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache[productID] = cacheEntry{info: info, expires: time.Now().Add(cacheTTL)}
+}
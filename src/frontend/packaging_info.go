@@ -6,6 +6,8 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/packaging"
 )
 
 /*
@@ -63,3 +65,18 @@ func httpGetPackagingInfo(productId string) (*PackagingInfo, error) {
 
 	return &packagingInfo, nil
 }
+
+// newPackagingClient wires the gRPC-streaming packaging client to the legacy HTTP lookup above, so
+// it can be used as the fallback whenever PACKAGING_SERVICE_GRPC_URL is unset or the stream fails.
+func newPackagingClient() (*packaging.Client, error) {
+	return packaging.NewClient(func(productID string) (*packaging.Info, error) {
+		if !isPackagingServiceConfigured() {
+			return nil, fmt.Errorf("packaging service not configured")
+		}
+		info, err := httpGetPackagingInfo(productID)
+		if err != nil {
+			return nil, err
+		}
+		return &packaging.Info{Weight: info.Weight, Width: info.Width, Height: info.Height, Depth: info.Depth}, nil
+	})
+}
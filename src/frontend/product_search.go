@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/frontend/genproto"
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/productsearch"
+)
+
+// catalogSearchWeight outranks every external provider by default: the internal catalog is the
+// source of truth, so a tie against a partner's copy of the same product should favor it.
+const catalogSearchWeight = 1.5
+
+// catalogSearchFunc adapts fe.productCatalogClient to productsearch.SearchFunc. ListProducts has
+// no query parameter, so this fetches the full catalog and filters client-side on a
+// case-insensitive substring match against the product name, same as the old
+// searchProductsForAdsHandler's upstream did.
+func (fe *frontendServer) catalogSearchFunc(ctx context.Context, query string) ([]productsearch.Item, error) {
+	resp, err := fe.productCatalogClient.ListProducts(ctx, &pb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	needle := strings.ToLower(strings.TrimSpace(query))
+	var items []productsearch.Item
+	for _, p := range resp.GetProducts() {
+		if needle != "" && !strings.Contains(strings.ToLower(p.GetName()), needle) {
+			continue
+		}
+		items = append(items, productsearch.Item{
+			ProductID: p.GetId(),
+			SKU:       p.GetId(),
+			Title:     p.GetName(),
+			ImageURL:  p.GetPicture(),
+			PriceUsd:  float64(p.GetPriceUsd().GetUnits()) + float64(p.GetPriceUsd().GetNanos())/1e9,
+			Score:     1,
+		})
+	}
+	return items, nil
+}
+
+// newProductSearchAggregator wires the internal catalog plus any PRODUCT_SEARCH_PROVIDERS
+// external sources (see productsearch.ProvidersFromEnv) into a productsearch.Aggregator for
+// searchProductsForAdsHandler.
+func (fe *frontendServer) newProductSearchAggregator() *productsearch.Aggregator {
+	sourceTimeout := productsearch.SourceTimeoutFromEnv()
+
+	sources := []productsearch.Source{
+		{Name: "catalog", Weight: catalogSearchWeight, Timeout: sourceTimeout, Search: fe.catalogSearchFunc},
+	}
+	for _, p := range productsearch.ProvidersFromEnv() {
+		sources = append(sources, productsearch.Source{
+			Name:    p.Name,
+			Weight:  p.Weight,
+			Timeout: sourceTimeout,
+			Search:  productsearch.HTTPProviderSearchFunc(fe.httpClient, p.URL),
+		})
+	}
+
+	return productsearch.New(sources, sourceTimeout,
+		productsearch.CacheSizeFromEnv(), productsearch.CacheTTLFromEnv(), productsearch.CacheSWRFromEnv())
+}
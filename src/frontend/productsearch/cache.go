@@ -0,0 +1,87 @@
+package productsearch
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	key     string
+	items   []Item
+	failed  []string
+	expires time.Time // fresh until this time
+	stale   time.Time // usable, stale-while-revalidate, until this time
+}
+
+// cache is a small LRU of merged Aggregator.Search results keyed by normalized query, with a TTL
+// freshness window and a stale-while-revalidate grace period past it.
+type cache struct {
+	mu       sync.Mutex
+	maxSize  int
+	ttl      time.Duration
+	swr      time.Duration
+	ll       *list.List
+	elements map[string]*list.Element
+}
+
+func newCache(maxSize int, ttl, swr time.Duration) *cache {
+	return &cache{
+		maxSize:  maxSize,
+		ttl:      ttl,
+		swr:      swr,
+		ll:       list.New(),
+		elements: make(map[string]*list.Element),
+	}
+}
+
+// get returns the entry for key and whether it's still within its TTL ("fresh"). The third
+// return value is false once the entry has aged past its stale-while-revalidate window, or never
+// existed.
+func (c *cache) get(key string) (cacheEntry, bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.elements[key]
+	if !ok {
+		return cacheEntry{}, false, false
+	}
+	entry := el.Value.(cacheEntry)
+	now := time.Now()
+	if now.After(entry.stale) {
+		c.ll.Remove(el)
+		delete(c.elements, key)
+		return cacheEntry{}, false, false
+	}
+	c.ll.MoveToFront(el)
+	return entry, now.Before(entry.expires), true
+}
+
+func (c *cache) set(key string, items []Item, failed []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	entry := cacheEntry{
+		key:     key,
+		items:   items,
+		failed:  failed,
+		expires: now.Add(c.ttl),
+		stale:   now.Add(c.ttl + c.swr),
+	}
+	if el, ok := c.elements[key]; ok {
+		el.Value = entry
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	el := c.ll.PushFront(entry)
+	c.elements[key] = el
+	if c.ll.Len() > c.maxSize {
+		oldest := c.ll.Back()
+		if oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.elements, oldest.Value.(cacheEntry).key)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package productsearch
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults applied when the corresponding PRODUCT_SEARCH_* env var is unset or unparseable.
+const (
+	DefaultSourceTimeout = 800 * time.Millisecond
+	DefaultCacheSize     = 256
+	DefaultCacheTTL      = 30 * time.Second
+	DefaultCacheSWR      = 2 * time.Minute
+)
+
+// ProviderConfig is one externally-configured product-search provider, parsed out of
+// PRODUCT_SEARCH_PROVIDERS by ProvidersFromEnv.
+type ProviderConfig struct {
+	Name   string
+	URL    string
+	Weight float64
+}
+
+// ProvidersFromEnv parses PRODUCT_SEARCH_PROVIDERS, a comma-separated list of
+// "name=url@weight" entries (weight optional, defaults to 1.0), e.g.
+//
+//	PRODUCT_SEARCH_PROVIDERS=partner-a=http://partner-a.internal/search@0.8,partner-b=http://partner-b.internal/search
+//
+// Returns nil if the env var is unset; a malformed entry (missing "=") is skipped.
+func ProvidersFromEnv() []ProviderConfig {
+	raw := os.Getenv("PRODUCT_SEARCH_PROVIDERS")
+	if raw == "" {
+		return nil
+	}
+
+	var providers []ProviderConfig
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, rest, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		rawURL, weightStr, _ := strings.Cut(rest, "@")
+		weight := 1.0
+		if weightStr != "" {
+			if w, err := strconv.ParseFloat(weightStr, 64); err == nil {
+				weight = w
+			}
+		}
+		providers = append(providers, ProviderConfig{
+			Name:   strings.TrimSpace(name),
+			URL:    strings.TrimSpace(rawURL),
+			Weight: weight,
+		})
+	}
+	return providers
+}
+
+// SourceTimeoutFromEnv is the per-source deadline Aggregator.Search enforces before a slow
+// source is skipped, overridable via PRODUCT_SEARCH_SOURCE_TIMEOUT (a duration, e.g. "800ms").
+func SourceTimeoutFromEnv() time.Duration {
+	if v := os.Getenv("PRODUCT_SEARCH_SOURCE_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return DefaultSourceTimeout
+}
+
+// CacheSizeFromEnv is the merge cache's max entry count, overridable via
+// PRODUCT_SEARCH_CACHE_SIZE. 0 or negative disables caching.
+func CacheSizeFromEnv() int {
+	if v := os.Getenv("PRODUCT_SEARCH_CACHE_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return DefaultCacheSize
+}
+
+// CacheTTLFromEnv and CacheSWRFromEnv bound how long a merge cache entry is served fresh vs.
+// stale-while-revalidate, overridable via PRODUCT_SEARCH_CACHE_TTL and PRODUCT_SEARCH_CACHE_SWR
+// (durations, e.g. "30s"/"2m").
+func CacheTTLFromEnv() time.Duration {
+	if v := os.Getenv("PRODUCT_SEARCH_CACHE_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return DefaultCacheTTL
+}
+
+func CacheSWRFromEnv() time.Duration {
+	if v := os.Getenv("PRODUCT_SEARCH_CACHE_SWR"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return DefaultCacheSWR
+}
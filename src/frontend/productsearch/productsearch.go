@@ -0,0 +1,212 @@
+// Package productsearch fans a product-search query out to multiple catalog sources (the
+// internal product catalog plus any configured external providers) in parallel, merges and
+// deduplicates their results, and caches the merge so bursty typeahead traffic doesn't re-query
+// every source on every keystroke. See Aggregator.
+package productsearch
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Item is one normalized product result, merged across catalog/provider sources.
+type Item struct {
+	ProductID string
+	SKU       string
+	Title     string
+	ImageURL  string
+	PriceUsd  float64
+
+	// Source is the name of the Source that produced this item, filled in by Aggregator.Search.
+	Source string
+	// Score is the item's relevance as reported by its source, weighted by that source's Weight.
+	// Dedup keeps the highest-scoring copy of a given product; the merged list is ranked by it.
+	Score float64
+}
+
+// SearchFunc performs one source's lookup for query. It's injected rather than tied to a
+// transport so Aggregator has no dependency on gRPC or any particular provider's HTTP shape (see
+// the frontend's catalogSearchFunc and HTTPProviderSearchFunc).
+type SearchFunc func(ctx context.Context, query string) ([]Item, error)
+
+// Source is one product-search backend the Aggregator fans a query out to.
+type Source struct {
+	Name    string
+	Weight  float64
+	Timeout time.Duration // falls back to Aggregator's defaultTimeout when zero
+	Search  SearchFunc
+}
+
+// SourceResult reports one source's contribution to a Search call, either as it streams in via
+// Search's onPartial callback or, with Source set to "cache", when the whole result is served
+// from the merge cache.
+type SourceResult struct {
+	Source string
+	Items  []Item
+	Err    error
+}
+
+// Result is Aggregator.Search's merged outcome across every source.
+type Result struct {
+	// Items is the deduplicated, score-ranked merge across every source that responded in time.
+	Items []Item
+	// Failed lists the names of sources that errored or exceeded their per-source deadline; the
+	// search still succeeds with whatever the other sources returned.
+	Failed []string
+	// Stale is true when Items was served from the cache past its TTL (stale-while-revalidate)
+	// while a fresh copy is fetched in the background.
+	Stale bool
+}
+
+// Aggregator fans a query out to every registered Source in parallel, merges and deduplicates
+// the results by normalized product identity (lowercased title + canonical SKU), and caches the
+// merged result keyed by normalized query.
+type Aggregator struct {
+	sources        []Source
+	defaultTimeout time.Duration
+	cache          *cache
+}
+
+// New builds an Aggregator over sources. defaultTimeout bounds any Source with Timeout unset.
+// cacheSize enables an in-memory LRU of merged results, TTL/SWR bounding how long an entry is
+// served fresh vs. stale-while-revalidate; cacheSize <= 0 disables caching entirely.
+func New(sources []Source, defaultTimeout time.Duration, cacheSize int, ttl, swr time.Duration) *Aggregator {
+	a := &Aggregator{sources: sources, defaultTimeout: defaultTimeout}
+	if cacheSize > 0 {
+		a.cache = newCache(cacheSize, ttl, swr)
+	}
+	return a
+}
+
+// Search fans query out to every source, reporting each source's contribution to onPartial (may
+// be nil) as it arrives so a caller can stream progress (e.g. over SSE), then returns the
+// deduplicated, score-ranked merge. A source that errors or exceeds its deadline is recorded in
+// Result.Failed instead of failing the whole search.
+func (a *Aggregator) Search(ctx context.Context, query string, onPartial func(SourceResult)) Result {
+	norm := normalizeQuery(query)
+
+	if a.cache != nil {
+		if entry, fresh, ok := a.cache.get(norm); ok {
+			if onPartial != nil {
+				onPartial(SourceResult{Source: "cache", Items: entry.items})
+			}
+			if fresh {
+				return Result{Items: entry.items, Failed: entry.failed}
+			}
+			go a.revalidate(norm, query)
+			return Result{Items: entry.items, Failed: entry.failed, Stale: true}
+		}
+	}
+
+	result := a.fanOut(ctx, query, onPartial)
+	if a.cache != nil {
+		a.cache.set(norm, result.Items, result.Failed)
+	}
+	return result
+}
+
+// revalidate refreshes a stale cache entry in the background on behalf of a caller that was just
+// served it, detached from the triggering request's context since that request may return (and
+// cancel its context) before the refresh completes.
+func (a *Aggregator) revalidate(norm, query string) {
+	ctx, cancel := context.WithTimeout(context.Background(), a.defaultTimeout+2*time.Second)
+	defer cancel()
+	result := a.fanOut(ctx, query, nil)
+	a.cache.set(norm, result.Items, result.Failed)
+}
+
+func (a *Aggregator) fanOut(ctx context.Context, query string, onPartial func(SourceResult)) Result {
+	var (
+		mu     sync.Mutex
+		items  []Item
+		failed []string
+		wg     sync.WaitGroup
+	)
+
+	for _, src := range a.sources {
+		src := src
+		timeout := src.Timeout
+		if timeout <= 0 {
+			timeout = a.defaultTimeout
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			srcCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			found, err := src.Search(srcCtx, query)
+			if err != nil {
+				mu.Lock()
+				failed = append(failed, src.Name)
+				mu.Unlock()
+				if onPartial != nil {
+					onPartial(SourceResult{Source: src.Name, Err: err})
+				}
+				return
+			}
+
+			for i := range found {
+				found[i].Source = src.Name
+				if found[i].Score == 0 {
+					found[i].Score = 1
+				}
+				found[i].Score *= src.Weight
+			}
+
+			mu.Lock()
+			items = append(items, found...)
+			mu.Unlock()
+			if onPartial != nil {
+				onPartial(SourceResult{Source: src.Name, Items: found})
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Strings(failed)
+	return Result{Items: dedupe(items), Failed: failed}
+}
+
+// dedupe collapses items sharing a normalized identity down to the single highest-scoring copy,
+// then ranks the survivors by score descending.
+func dedupe(items []Item) []Item {
+	best := make(map[string]Item, len(items))
+	order := make([]string, 0, len(items))
+	for _, it := range items {
+		key := normalizedIdentity(it)
+		existing, ok := best[key]
+		if !ok {
+			order = append(order, key)
+		}
+		if !ok || it.Score > existing.Score {
+			best[key] = it
+		}
+	}
+
+	out := make([]Item, 0, len(order))
+	for _, key := range order {
+		out = append(out, best[key])
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
+
+// normalizedIdentity is how two Items from different sources are recognized as "the same
+// product": lowercase title plus a canonical SKU (the product ID, when a source has no SKU of
+// its own).
+func normalizedIdentity(it Item) string {
+	sku := strings.ToUpper(strings.TrimSpace(it.SKU))
+	if sku == "" {
+		sku = strings.ToUpper(strings.TrimSpace(it.ProductID))
+	}
+	return strings.ToLower(strings.TrimSpace(it.Title)) + "|" + sku
+}
+
+func normalizeQuery(q string) string {
+	return strings.ToLower(strings.TrimSpace(q))
+}
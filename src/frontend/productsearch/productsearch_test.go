@@ -0,0 +1,136 @@
+package productsearch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSearchDedupesAndRanksByWeightedScore(t *testing.T) {
+	catalog := Source{
+		Name:   "catalog",
+		Weight: 1.0,
+		Search: func(ctx context.Context, query string) ([]Item, error) {
+			return []Item{{ProductID: "SKU1", Title: "Vintage Camera"}}, nil
+		},
+	}
+	partner := Source{
+		Name:   "partner",
+		Weight: 2.0,
+		Search: func(ctx context.Context, query string) ([]Item, error) {
+			return []Item{{ProductID: "other-id", SKU: "sku1", Title: "vintage camera"}}, nil
+		},
+	}
+
+	a := New([]Source{catalog, partner}, time.Second, 0, 0, 0)
+	result := a.Search(context.Background(), "camera", nil)
+
+	if len(result.Items) != 1 {
+		t.Fatalf("expected the two sources' items to dedupe into one, got %d", len(result.Items))
+	}
+	if result.Items[0].Source != "partner" {
+		t.Errorf("expected the higher-weighted partner copy to win, got source %q", result.Items[0].Source)
+	}
+	if len(result.Failed) != 0 {
+		t.Errorf("expected no failed sources, got %v", result.Failed)
+	}
+}
+
+func TestSearchReportsFailingSourceWithoutFailingTheWholeSearch(t *testing.T) {
+	good := Source{
+		Name:   "catalog",
+		Weight: 1.0,
+		Search: func(ctx context.Context, query string) ([]Item, error) {
+			return []Item{{ProductID: "1", Title: "Lamp"}}, nil
+		},
+	}
+	bad := Source{
+		Name:   "partner",
+		Weight: 1.0,
+		Search: func(ctx context.Context, query string) ([]Item, error) {
+			return nil, errors.New("upstream unavailable")
+		},
+	}
+
+	a := New([]Source{good, bad}, time.Second, 0, 0, 0)
+	result := a.Search(context.Background(), "lamp", nil)
+
+	if len(result.Items) != 1 {
+		t.Fatalf("expected the healthy source's item to still be returned, got %d items", len(result.Items))
+	}
+	if len(result.Failed) != 1 || result.Failed[0] != "partner" {
+		t.Errorf("expected partner reported as failed, got %v", result.Failed)
+	}
+}
+
+func TestSearchSkipsASourceThatExceedsItsDeadline(t *testing.T) {
+	slow := Source{
+		Name:    "slow",
+		Weight:  1.0,
+		Timeout: 10 * time.Millisecond,
+		Search: func(ctx context.Context, query string) ([]Item, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+
+	a := New([]Source{slow}, time.Second, 0, 0, 0)
+	result := a.Search(context.Background(), "q", nil)
+
+	if len(result.Items) != 0 {
+		t.Fatalf("expected no items from the slow source, got %d", len(result.Items))
+	}
+	if len(result.Failed) != 1 || result.Failed[0] != "slow" {
+		t.Errorf("expected the slow source reported as failed, got %v", result.Failed)
+	}
+}
+
+func TestSearchServesFromCacheWithoutRequeryingSources(t *testing.T) {
+	calls := 0
+	src := Source{
+		Name:   "catalog",
+		Weight: 1.0,
+		Search: func(ctx context.Context, query string) ([]Item, error) {
+			calls++
+			return []Item{{ProductID: "1", Title: "Mug"}}, nil
+		},
+	}
+
+	a := New([]Source{src}, time.Second, 16, time.Minute, time.Minute)
+	a.Search(context.Background(), "mug", nil)
+	a.Search(context.Background(), "Mug", nil) // same normalized query, different case
+
+	if calls != 1 {
+		t.Errorf("expected the second search to hit the cache, source was called %d times", calls)
+	}
+}
+
+func TestSearchServesStaleEntryAndRevalidatesInBackground(t *testing.T) {
+	calls := make(chan struct{}, 2)
+	src := Source{
+		Name:   "catalog",
+		Weight: 1.0,
+		Search: func(ctx context.Context, query string) ([]Item, error) {
+			calls <- struct{}{}
+			return []Item{{ProductID: "1", Title: "Mug"}}, nil
+		},
+	}
+
+	a := New([]Source{src}, time.Second, 16, time.Millisecond, time.Minute)
+	a.Search(context.Background(), "mug", nil)
+	<-calls
+
+	time.Sleep(5 * time.Millisecond) // let the entry go stale
+
+	result := a.Search(context.Background(), "mug", nil)
+	if len(result.Items) != 1 || !result.Stale {
+		t.Fatalf("expected a stale cache hit to still return data, got %+v", result)
+	}
+
+	select {
+	case <-calls:
+	case <-time.After(time.Second):
+		t.Error("expected a background revalidation call, got none")
+	}
+}
@@ -0,0 +1,58 @@
+package productsearch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// providerItem is the JSON shape an external product-search provider is expected to respond
+// with: a bare JSON array of these objects.
+type providerItem struct {
+	ProductID string  `json:"product_id"`
+	SKU       string  `json:"sku"`
+	Title     string  `json:"title"`
+	ImageURL  string  `json:"image_url"`
+	PriceUsd  float64 `json:"price_usd"`
+}
+
+// HTTPProviderSearchFunc builds a SearchFunc that queries an external provider's search endpoint
+// (baseURL with "?q=<query>" appended) over client and decodes a JSON array of providerItem.
+func HTTPProviderSearchFunc(client *http.Client, baseURL string) SearchFunc {
+	return func(ctx context.Context, query string) ([]Item, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"?q="+url.QueryEscape(query), nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "productsearch: failed to build provider request")
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, errors.Wrap(err, "productsearch: provider request failed")
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("productsearch: provider returned status %d", resp.StatusCode)
+		}
+
+		var raw []providerItem
+		if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+			return nil, errors.Wrap(err, "productsearch: failed to decode provider response")
+		}
+
+		items := make([]Item, len(raw))
+		for i, it := range raw {
+			items[i] = Item{
+				ProductID: it.ProductID,
+				SKU:       it.SKU,
+				Title:     it.Title,
+				ImageURL:  it.ImageURL,
+				PriceUsd:  it.PriceUsd,
+				Score:     1,
+			}
+		}
+		return items, nil
+	}
+}
@@ -0,0 +1,102 @@
+// Package resilience provides a per-client gRPC interceptor (deadline + exponential-backoff retry
+// + circuit breaker) and a matching circuit breaker for plain HTTP calls, so outbound calls from
+// frontendServer stop piling up goroutines/connections against a backend that is already down.
+package resilience
+
+import (
+	"sync"
+	"time"
+)
+
+type breakerState int
+
+const (
+	stateClosed breakerState = iota
+	stateOpen
+	stateHalfOpen
+)
+
+// Breaker is a minimal circuit breaker: it trips to open after Threshold consecutive failures,
+// stays open for Cooldown, then allows a single trial call through (half-open) before deciding
+// whether to close again or re-open.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu           sync.Mutex
+	state        breakerState
+	failures     int
+	openedAt     time.Time
+	halfOpenBusy bool
+}
+
+// NewBreaker returns a Breaker that opens after threshold consecutive failures and stays open for
+// cooldown before probing again. threshold <= 0 disables tripping (Allow always returns true).
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{threshold: threshold, cooldown: cooldown}
+}
+
+// Allow reports whether a call should proceed. It returns true for a closed breaker, false while
+// open and within the cooldown window, and true exactly once per cooldown window (half-open) to
+// probe whether the dependency has recovered.
+func (b *Breaker) Allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case stateClosed:
+		return true
+	case stateOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = stateHalfOpen
+		b.halfOpenBusy = true
+		return true
+	case stateHalfOpen:
+		if b.halfOpenBusy {
+			return false
+		}
+		b.halfOpenBusy = true
+		return true
+	}
+	return true
+}
+
+// Success resets the breaker to closed.
+func (b *Breaker) Success() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = stateClosed
+	b.failures = 0
+	b.halfOpenBusy = false
+}
+
+// Failure records a failed call, tripping the breaker open once Threshold consecutive failures
+// (or a failed half-open probe) have been seen.
+func (b *Breaker) Failure() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == stateHalfOpen {
+		b.halfOpenBusy = false
+		b.state = stateOpen
+		b.openedAt = time.Now()
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.state = stateOpen
+		b.openedAt = time.Now()
+	}
+}
@@ -0,0 +1,139 @@
+package resilience
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Policy configures the per-RPC deadline and retry behavior applied by UnaryClientInterceptor.
+type Policy struct {
+	Timeout          time.Duration
+	MaxAttempts      int // total attempts, including the first; 1 disables retries
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// DefaultPolicy is applied to any service whose env vars aren't set.
+var DefaultPolicy = Policy{
+	Timeout:          3 * time.Second,
+	MaxAttempts:      3,
+	BaseDelay:        50 * time.Millisecond,
+	MaxDelay:         2 * time.Second,
+	BreakerThreshold: 5,
+	BreakerCooldown:  30 * time.Second,
+}
+
+// PolicyFromEnv builds a Policy for a service, reading <prefix>_TIMEOUT, <prefix>_MAX_RETRIES,
+// <prefix>_BREAKER_THRESHOLD and <prefix>_BREAKER_COOLDOWN (falling back to DefaultPolicy for any
+// that are unset or unparseable). prefix matches the service's *_ADDR env var minus the _ADDR
+// suffix, e.g. "CHECKOUT_SERVICE" for CHECKOUT_SERVICE_ADDR.
+func PolicyFromEnv(prefix string) Policy {
+	p := DefaultPolicy
+	if v, ok := DurationEnv(prefix + "_TIMEOUT"); ok {
+		p.Timeout = v
+	}
+	if v, ok := IntEnv(prefix + "_MAX_RETRIES"); ok {
+		p.MaxAttempts = v + 1
+	}
+	if v, ok := IntEnv(prefix + "_BREAKER_THRESHOLD"); ok {
+		p.BreakerThreshold = v
+	}
+	if v, ok := DurationEnv(prefix + "_BREAKER_COOLDOWN"); ok {
+		p.BreakerCooldown = v
+	}
+	return p
+}
+
+// DurationEnv and IntEnv parse key as a time.Duration/int, returning ok=false if key is unset or
+// unparseable. Exported so other packages building env-driven policies on top of Policy (e.g.
+// httpx.RoutePolicyFromEnv) don't duplicate the parsing.
+func DurationEnv(key string) (time.Duration, bool) {
+	s := os.Getenv(key)
+	if s == "" {
+		return 0, false
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, false
+	}
+	return d, true
+}
+
+func IntEnv(key string) (int, bool) {
+	s := os.Getenv(key)
+	if s == "" {
+		return 0, false
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// UnaryClientInterceptor applies policy.Timeout as a per-call deadline, retries attempts on
+// Unavailable/DeadlineExceeded with exponential backoff and jitter up to policy.MaxAttempts, and
+// consults breaker before every attempt so a tripped breaker fails fast without dialing out at
+// all. breaker may be nil to disable breaker behavior for this client.
+func UnaryClientInterceptor(policy Policy, breaker *Breaker) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		var err error
+		for attempt := 0; attempt < policy.MaxAttempts; attempt++ {
+			if breaker != nil && !breaker.Allow() {
+				return status.Errorf(codes.Unavailable, "%s: circuit breaker open", method)
+			}
+
+			callCtx, cancel := context.WithTimeout(ctx, policy.Timeout)
+			err = invoker(callCtx, method, req, reply, cc, opts...)
+			cancel()
+
+			if err == nil {
+				if breaker != nil {
+					breaker.Success()
+				}
+				return nil
+			}
+			if breaker != nil {
+				breaker.Failure()
+			}
+			if !retriable(err) || attempt == policy.MaxAttempts-1 {
+				return err
+			}
+			time.Sleep(Backoff(attempt, policy.BaseDelay, policy.MaxDelay))
+		}
+		return err
+	}
+}
+
+func retriable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// Backoff returns BaseDelay*2^attempt, capped at MaxDelay, with +/-25% jitter so a thundering herd
+// of retrying callers doesn't re-hit the backend in lockstep.
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	d := base << attempt
+	if d <= 0 || d > max {
+		d = max
+	}
+	jitter := time.Duration(rand.Int63n(int64(d)/2+1)) - d/4
+	d += jitter
+	if d < 0 {
+		d = base
+	}
+	return d
+}
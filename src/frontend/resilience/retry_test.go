@@ -0,0 +1,70 @@
+package resilience
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeInvoker simulates a backend that fails N times before succeeding, so tests can drive
+// UnaryClientInterceptor without a real grpc.ClientConn.
+func fakeInvoker(failures int, code codes.Code) (grpc.UnaryInvoker, *int) {
+	calls := 0
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls++
+		if calls <= failures {
+			return status.Error(code, "backend unavailable")
+		}
+		return nil
+	}, &calls
+}
+
+func TestUnaryClientInterceptorRetriesOnUnavailable(t *testing.T) {
+	policy := Policy{Timeout: time.Second, MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+	invoker, calls := fakeInvoker(2, codes.Unavailable)
+	interceptor := UnaryClientInterceptor(policy, nil)
+
+	err := interceptor(context.Background(), "/test/Method", nil, nil, nil, invoker)
+	if err != nil {
+		t.Fatalf("expected eventual success, got %v", err)
+	}
+	if *calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", *calls)
+	}
+}
+
+func TestUnaryClientInterceptorDoesNotRetryNonRetriableCodes(t *testing.T) {
+	policy := Policy{Timeout: time.Second, MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+	invoker, calls := fakeInvoker(2, codes.InvalidArgument)
+	interceptor := UnaryClientInterceptor(policy, nil)
+
+	err := interceptor(context.Background(), "/test/Method", nil, nil, nil, invoker)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument to surface immediately, got %v", err)
+	}
+	if *calls != 1 {
+		t.Fatalf("expected exactly 1 attempt for a non-retriable error, got %d", *calls)
+	}
+}
+
+func TestUnaryClientInterceptorTripsBreaker(t *testing.T) {
+	policy := Policy{Timeout: time.Second, MaxAttempts: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	breaker := NewBreaker(2, time.Minute)
+	invoker, calls := fakeInvoker(100, codes.Unavailable)
+	interceptor := UnaryClientInterceptor(policy, breaker)
+
+	for i := 0; i < 2; i++ {
+		if err := interceptor(context.Background(), "/test/Method", nil, nil, nil, invoker); status.Code(err) != codes.Unavailable {
+			t.Fatalf("call %d: expected Unavailable, got %v", i, err)
+		}
+	}
+
+	err := interceptor(context.Background(), "/test/Method", nil, nil, nil, invoker)
+	if status.Code(err) != codes.Unavailable || *calls != 2 {
+		t.Fatalf("expected breaker to fail fast without invoking the backend a 3rd time, calls=%d err=%v", *calls, err)
+	}
+}
@@ -0,0 +1,122 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxEventSize bounds how large a single frame may be before Decoder gives up, generous enough
+// for a full chat reply or ad-copy payload in one SSE event.
+const maxEventSize = 1 << 20 // 1MiB
+
+// Decoder splits an upstream byte stream into whole SSE frames (terminated by a blank line)
+// regardless of how the underlying reads happen to chunk the bytes, so a frame is never handed to
+// the caller half-written.
+type Decoder struct {
+	scanner *bufio.Scanner
+	// unterminated is set when the frame last produced by the scanner ended at end of input
+	// rather than at a blank line.
+	unterminated bool
+}
+
+// NewDecoder wraps r, most commonly an upstream http.Response.Body.
+func NewDecoder(r io.Reader) *Decoder {
+	d := &Decoder{}
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 4096), maxEventSize)
+	s.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		i, _ := blankLineIndex(data)
+		d.unterminated = atEOF && i < 0
+		return splitFrames(data, atEOF)
+	})
+	d.scanner = s
+	return d
+}
+
+// Next returns the next Event and true, or a zero Event and false once the stream ends (check Err
+// to distinguish a clean EOF from a read error). A final frame without a terminating blank line is
+// returned when the stream ends cleanly, and dropped when it ends with a read error.
+func (d *Decoder) Next() (Event, bool) {
+	if !d.scanner.Scan() {
+		return Event{}, false
+	}
+	if d.unterminated && d.scanner.Err() != nil {
+		return Event{}, false
+	}
+	return ParseEvent(d.scanner.Text()), true
+}
+
+// Err returns the first non-EOF error encountered while scanning, if any.
+func (d *Decoder) Err() error {
+	return d.scanner.Err()
+}
+
+// splitFrames is a bufio.SplitFunc that splits on the blank line ("\n\n", or "\r\n\r\n") that
+// terminates every SSE frame, instead of bufio.ScanLines' one-line-at-a-time behavior.
+func splitFrames(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i, sepLen := blankLineIndex(data); i >= 0 {
+		return i + sepLen, normalizeNewlines(data[:i]), nil
+	}
+	if atEOF {
+		return len(data), normalizeNewlines(data), nil
+	}
+	return 0, nil, nil // request more data
+}
+
+// blankLineIndex finds the earliest blank-line frame terminator in data — LF ("\n\n") or CRLF
+// ("\r\n\r\n") — and returns its index along with the number of bytes it occupies. bytes.Index
+// can't find "\r\n\r\n" by searching for "\n\n" alone, since the two newlines in a CRLF blank line
+// have a "\r" between them; both forms are valid per the SSE spec, so both must be checked.
+func blankLineIndex(data []byte) (index, sepLen int) {
+	lf := bytes.Index(data, []byte("\n\n"))
+	crlf := bytes.Index(data, []byte("\r\n\r\n"))
+	switch {
+	case lf < 0:
+		return crlf, 4
+	case crlf < 0 || lf < crlf:
+		return lf, 2
+	default:
+		return crlf, 4
+	}
+}
+
+func normalizeNewlines(b []byte) []byte {
+	return bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))
+}
+
+// ParseEvent parses the raw field lines of a single frame (as produced by splitFrames) into an
+// Event. Comment lines (leading ':') and unrecognized field names are ignored, per the SSE spec.
+func ParseEvent(raw string) Event {
+	var e Event
+	var data []string
+	for _, line := range strings.Split(raw, "\n") {
+		if line == "" || strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value := line, ""
+		if i := strings.Index(line, ":"); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "id":
+			e.ID = value
+		case "event":
+			e.Event = value
+		case "data":
+			data = append(data, value)
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil {
+				e.Retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	e.Data = strings.Join(data, "\n")
+	return e
+}
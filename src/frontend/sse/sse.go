@@ -0,0 +1,47 @@
+// Package sse implements a small Server-Sent-Events encoder/decoder, so a streaming HTTP response
+// can be proxied frame-by-frame (see chatStreamHandler) instead of copying raw bytes, which risks
+// splitting an "event:"/"data:" line across a read boundary.
+package sse
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Event is one SSE frame. Data may contain embedded newlines; Encode splits it into one "data:"
+// line per line, per the SSE spec, so multi-line payloads round-trip correctly.
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+	Retry time.Duration
+}
+
+// Encode renders e as a complete SSE frame, terminated by the blank line that marks its end.
+func (e Event) Encode() []byte {
+	var b bytes.Buffer
+	if e.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", e.ID)
+	}
+	if e.Event != "" {
+		fmt.Fprintf(&b, "event: %s\n", e.Event)
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", e.Retry.Milliseconds())
+	}
+	if e.Data != "" {
+		for _, line := range strings.Split(e.Data, "\n") {
+			fmt.Fprintf(&b, "data: %s\n", line)
+		}
+	}
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+// Comment encodes an SSE comment frame (e.g. ": keepalive"). Clients ignore comments, but they
+// keep intermediaries (proxies, load balancers) from treating an idle connection as dead.
+func Comment(text string) []byte {
+	return []byte(": " + text + "\n\n")
+}
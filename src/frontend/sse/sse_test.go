@@ -0,0 +1,124 @@
+package sse
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventEncodeAndParseRoundTrip(t *testing.T) {
+	e := Event{ID: "42", Event: "message", Data: "line one\nline two", Retry: 3 * time.Second}
+	got := ParseEvent(strings.TrimSuffix(string(e.Encode()), "\n\n"))
+	if got != e {
+		t.Fatalf("round-trip mismatch: got %+v, want %+v", got, e)
+	}
+}
+
+func TestDecoderMultiLineData(t *testing.T) {
+	raw := "event: greeting\ndata: hello\ndata: world\n\n"
+	dec := NewDecoder(strings.NewReader(raw))
+
+	ev, ok := dec.Next()
+	if !ok {
+		t.Fatalf("expected an event, got none (err=%v)", dec.Err())
+	}
+	if ev.Event != "greeting" || ev.Data != "hello\nworld" {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+	if _, ok := dec.Next(); ok {
+		t.Fatalf("expected stream to end after the single frame")
+	}
+}
+
+func TestDecoderSplitsCRLFTerminatedFrames(t *testing.T) {
+	raw := "event: greeting\r\ndata: hello\r\ndata: world\r\n\r\ndata: second\r\n\r\n"
+	dec := NewDecoder(strings.NewReader(raw))
+
+	first, ok := dec.Next()
+	if !ok {
+		t.Fatalf("expected a first event, got none (err=%v)", dec.Err())
+	}
+	if first.Event != "greeting" || first.Data != "hello\nworld" {
+		t.Fatalf("unexpected first event: %+v", first)
+	}
+
+	second, ok := dec.Next()
+	if !ok || second.Data != "second" {
+		t.Fatalf("expected second event %q, ok=%v", second.Data, ok)
+	}
+	if _, ok := dec.Next(); ok {
+		t.Fatalf("expected stream to end after the two frames")
+	}
+}
+
+// slowReader drips the underlying bytes one at a time (and, for multi-byte runes, mid-rune) to
+// make sure the frame splitter never depends on a read boundary lining up with a UTF-8 boundary
+// or a "\n\n" separator.
+type slowReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if r.pos >= len(r.data) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.data[r.pos:r.pos+1])
+	r.pos += n
+	return n, nil
+}
+
+func TestDecoderSurvivesByteAtATimeUTF8Reads(t *testing.T) {
+	raw := "data: caf\xc3\xa9 \xe2\x98\x95\n\ndata: second\n\n"
+	dec := NewDecoder(&slowReader{data: []byte(raw)})
+
+	first, ok := dec.Next()
+	if !ok {
+		t.Fatalf("expected first event, got none (err=%v)", dec.Err())
+	}
+	if first.Data != "café ☕" {
+		t.Fatalf("expected UTF-8 data to survive byte-at-a-time reads, got %q", first.Data)
+	}
+
+	second, ok := dec.Next()
+	if !ok || second.Data != "second" {
+		t.Fatalf("expected second event %q, ok=%v", second.Data, ok)
+	}
+}
+
+func TestDecoderFlushesFinalFrameWithoutTrailingBlankLine(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("data: no trailing blank line"))
+	ev, ok := dec.Next()
+	if !ok || ev.Data != "no trailing blank line" {
+		t.Fatalf("expected the unterminated final frame to still be returned, got %+v ok=%v", ev, ok)
+	}
+}
+
+// abortingReader errors out partway through, simulating a client/upstream abort mid-stream.
+type abortingReader struct {
+	data []byte
+	err  error
+}
+
+func (r *abortingReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestDecoderSurfacesAbortError(t *testing.T) {
+	abortErr := context.Canceled
+	dec := NewDecoder(&abortingReader{data: []byte("data: partial"), err: abortErr})
+
+	if _, ok := dec.Next(); ok {
+		t.Fatalf("expected no complete frame before the abort")
+	}
+	if dec.Err() != abortErr {
+		t.Fatalf("expected Err() to surface the abort error, got %v", dec.Err())
+	}
+}
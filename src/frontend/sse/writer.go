@@ -0,0 +1,65 @@
+package sse
+
+import (
+	"bufio"
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Writer buffers encoded frames and flushes them to an http.ResponseWriter on demand, so a
+// caller can coalesce several events into one flush (see chatStreamHandler's flush ticker)
+// instead of flushing after every single write.
+type Writer struct {
+	mu      sync.Mutex
+	bw      *bufio.Writer
+	flusher http.Flusher
+	dirty   bool
+}
+
+// NewWriter wraps w, which must implement http.Flusher (true for the ResponseWriter passed to any
+// net/http handler).
+func NewWriter(w http.ResponseWriter) (*Writer, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errors.New("sse: response writer does not support flushing")
+	}
+	return &Writer{bw: bufio.NewWriter(w), flusher: flusher}, nil
+}
+
+// WriteEvent buffers e's encoded frame. Call Flush to push buffered frames to the client.
+func (w *Writer) WriteEvent(e Event) error {
+	return w.write(e.Encode())
+}
+
+// WriteComment buffers a keepalive/comment frame (see Comment).
+func (w *Writer) WriteComment(text string) error {
+	return w.write(Comment(text))
+}
+
+func (w *Writer) write(frame []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.bw.Write(frame); err != nil {
+		return errors.Wrap(err, "sse: failed to buffer frame")
+	}
+	w.dirty = true
+	return nil
+}
+
+// Flush pushes any frames buffered since the last Flush to the client. It's a no-op if nothing
+// new has been written.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.dirty {
+		return nil
+	}
+	if err := w.bw.Flush(); err != nil {
+		return errors.Wrap(err, "sse: failed to flush")
+	}
+	w.flusher.Flush()
+	w.dirty = false
+	return nil
+}
@@ -0,0 +1,52 @@
+package telemetry
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// newTraceExporter builds the sdktrace.SpanExporter selected by OTEL_EXPORTER:
+//
+//   - "otlp" (the default) or "datadog": OTLP/gRPC against OTEL_EXPORTER_OTLP_ENDPOINT. Datadog
+//     is reached the same way — point the endpoint at the Datadog Agent's OTLP receiver.
+//   - "stackdriver": OTLP/gRPC against Cloud Trace's OTLP endpoint, set via
+//     OTEL_EXPORTER_OTLP_ENDPOINT the same as the default case.
+//   - "jaeger": Jaeger's collector HTTP endpoint, via OTEL_EXPORTER_JAEGER_ENDPOINT.
+//   - "stdout": spans printed to stdout, for local development without a collector.
+func newTraceExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(os.Getenv("OTEL_EXPORTER")) {
+	case "jaeger":
+		endpoint := os.Getenv("OTEL_EXPORTER_JAEGER_ENDPOINT")
+		if endpoint == "" {
+			endpoint = "http://localhost:14268/api/traces"
+		}
+		return jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(endpoint)))
+	case "stdout":
+		return stdouttrace.New()
+	case "", "otlp", "stackdriver", "datadog":
+		return newOTLPExporter(ctx)
+	default:
+		return nil, errors.Errorf("telemetry: unknown OTEL_EXPORTER %q", os.Getenv("OTEL_EXPORTER"))
+	}
+}
+
+func newOTLPExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "localhost:4317"
+	}
+
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint)}
+	if os.Getenv("OTEL_EXPORTER_OTLP_INSECURE") != "0" {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	return otlptracegrpc.New(ctx, opts...)
+}
@@ -0,0 +1,15 @@
+package telemetry
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// WrapHandler instruments h with otelhttp: every request gets a span (named operation,
+// attributed to serviceName) plus the standard otelhttp request-count/duration/in-flight
+// metrics, and propagates/extracts trace context per Init's configured propagator. It replaces
+// the old httptrace (Datadog gorilla/mux) router wrapper.
+func WrapHandler(serviceName string, h http.Handler) http.Handler {
+	return otelhttp.NewHandler(h, serviceName)
+}
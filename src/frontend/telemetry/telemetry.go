@@ -0,0 +1,93 @@
+// Package telemetry wires frontendServer's tracing and metrics through OpenTelemetry: a
+// configurable trace exporter (OTLP, Jaeger, Stackdriver, or Datadog-via-OTLP) selected by
+// OTEL_EXPORTER, B3 + W3C trace-context propagation so spans cross service boundaries (cart,
+// checkout, product catalog, peau-agent, video-generation), and a Prometheus-backed
+// MeterProvider so otelhttp/otelgrpc's request-count/latency/error metrics surface on the
+// existing /metrics endpoint. It replaces the old hard-wired gopkg.in/DataDog/dd-trace-go.v1
+// setup.
+package telemetry
+
+import (
+	"context"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// Shutdown flushes and stops the providers Init installed. Call it once during graceful
+// shutdown, mirroring the old tracer.Stop().
+type Shutdown func(context.Context) error
+
+// Init builds a trace exporter from OTEL_EXPORTER and OTEL_EXPORTER_OTLP_ENDPOINT (see
+// newTraceExporter), installs it on a TracerProvider and a Prometheus-backed MeterProvider (read
+// by the existing /metrics promhttp.Handler) as the OpenTelemetry globals, and sets a composite
+// B3 + W3C trace-context + baggage propagator so spans survive a hop through any downstream
+// service regardless of which convention it speaks.
+func Init(ctx context.Context, serviceName string) (Shutdown, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(serviceVersion()),
+		semconv.DeploymentEnvironment(deploymentEnv()),
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "telemetry: failed to build resource")
+	}
+
+	traceExporter, err := newTraceExporter(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "telemetry: failed to build trace exporter")
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(traceExporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+
+	metricExporter, err := prometheus.New()
+	if err != nil {
+		return nil, errors.Wrap(err, "telemetry: failed to build prometheus metric exporter")
+	}
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(metricExporter),
+		sdkmetric.WithResource(res),
+	)
+	otel.SetMeterProvider(mp)
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		b3.New(),
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return func(shutdownCtx context.Context) error {
+		if err := tp.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		return mp.Shutdown(shutdownCtx)
+	}, nil
+}
+
+// serviceVersion and deploymentEnv mirror the old DD_VERSION/DD_ENV knobs, overridable via
+// OTEL_SERVICE_VERSION and OTEL_DEPLOYMENT_ENVIRONMENT.
+func serviceVersion() string {
+	if v := os.Getenv("OTEL_SERVICE_VERSION"); v != "" {
+		return v
+	}
+	return "1.0.0"
+}
+
+func deploymentEnv() string {
+	if v := os.Getenv("OTEL_DEPLOYMENT_ENVIRONMENT"); v != "" {
+		return v
+	}
+	return "hackathon"
+}
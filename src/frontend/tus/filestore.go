@@ -0,0 +1,229 @@
+package tus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultExpiry is how long an upload may sit incomplete before GC reclaims it.
+const DefaultExpiry = 24 * time.Hour
+
+// FileStore is a Store backed by the local filesystem: each upload gets its own directory holding
+// its assembled bytes (data) and a JSON sidecar (info) tracking size/offset/metadata/expiry.
+type FileStore struct {
+	baseDir string
+	expiry  time.Duration
+
+	mu   sync.Mutex
+	lock map[string]*sync.Mutex // per-upload-ID mutex, guarding concurrent PATCHes to the same ID
+}
+
+// NewFileStore builds a FileStore rooted at baseDir, creating it if necessary.
+func NewFileStore(baseDir string, expiry time.Duration) (*FileStore, error) {
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, errors.Wrap(err, "tus: failed to create upload directory")
+	}
+	return &FileStore{baseDir: baseDir, expiry: expiry, lock: make(map[string]*sync.Mutex)}, nil
+}
+
+type fileInfo struct {
+	ID        string            `json:"id"`
+	Size      int64             `json:"size"`
+	Offset    int64             `json:"offset"`
+	Metadata  map[string]string `json:"metadata"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+func (s *FileStore) dir(id string) string      { return filepath.Join(s.baseDir, id) }
+func (s *FileStore) dataPath(id string) string { return filepath.Join(s.dir(id), "data") }
+func (s *FileStore) infoPath(id string) string { return filepath.Join(s.dir(id), "info.json") }
+
+func (s *FileStore) readInfo(id string) (fileInfo, error) {
+	b, err := os.ReadFile(s.infoPath(id))
+	if os.IsNotExist(err) {
+		return fileInfo{}, ErrNotFound
+	}
+	if err != nil {
+		return fileInfo{}, errors.Wrap(err, "tus: failed to read upload metadata")
+	}
+	var info fileInfo
+	if err := json.Unmarshal(b, &info); err != nil {
+		return fileInfo{}, errors.Wrap(err, "tus: failed to decode upload metadata")
+	}
+	return info, nil
+}
+
+func (s *FileStore) writeInfo(info fileInfo) error {
+	b, err := json.Marshal(info)
+	if err != nil {
+		return errors.Wrap(err, "tus: failed to encode upload metadata")
+	}
+	if err := os.WriteFile(s.infoPath(info.ID), b, 0o644); err != nil {
+		return errors.Wrap(err, "tus: failed to write upload metadata")
+	}
+	return nil
+}
+
+func (s *FileStore) idMutex(id string) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	mu, ok := s.lock[id]
+	if !ok {
+		mu = &sync.Mutex{}
+		s.lock[id] = mu
+	}
+	return mu
+}
+
+func newUploadID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "tus: failed to generate upload id")
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (s *FileStore) Create(ctx context.Context, size int64, metadata map[string]string) (Upload, error) {
+	id, err := newUploadID()
+	if err != nil {
+		return Upload{}, err
+	}
+	if err := os.MkdirAll(s.dir(id), 0o755); err != nil {
+		return Upload{}, errors.Wrap(err, "tus: failed to create upload directory")
+	}
+	if f, err := os.Create(s.dataPath(id)); err != nil {
+		return Upload{}, errors.Wrap(err, "tus: failed to create upload data file")
+	} else {
+		f.Close()
+	}
+
+	info := fileInfo{
+		ID:        id,
+		Size:      size,
+		Offset:    0,
+		Metadata:  metadata,
+		ExpiresAt: time.Now().Add(s.expiry),
+	}
+	if err := s.writeInfo(info); err != nil {
+		return Upload{}, err
+	}
+	return uploadFromInfo(info), nil
+}
+
+func (s *FileStore) Get(ctx context.Context, id string) (Upload, error) {
+	info, err := s.readInfo(id)
+	if err != nil {
+		return Upload{}, err
+	}
+	if time.Now().After(info.ExpiresAt) {
+		return Upload{}, ErrNotFound
+	}
+	return uploadFromInfo(info), nil
+}
+
+func (s *FileStore) WriteChunk(ctx context.Context, id string, offset int64, r io.Reader) (int64, error) {
+	mu := s.idMutex(id)
+	mu.Lock()
+	defer mu.Unlock()
+
+	info, err := s.readInfo(id)
+	if err != nil {
+		return 0, err
+	}
+	if offset != info.Offset {
+		return 0, ErrOffsetMismatch
+	}
+
+	f, err := os.OpenFile(s.dataPath(id), os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, errors.Wrap(err, "tus: failed to open upload data file")
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, errors.Wrap(err, "tus: failed to seek upload data file")
+	}
+
+	n, err := io.Copy(f, r)
+	if err != nil {
+		// r may be a checksumReader (see handler.go's verifyingReader), in which case err can be
+		// ErrChecksumMismatch: a sentinel patch compares against with == , so it must reach the
+		// caller unwrapped rather than folded into an opaque wrapped error.
+		if err == ErrChecksumMismatch {
+			return 0, err
+		}
+		return 0, errors.Wrap(err, "tus: failed to write upload chunk")
+	}
+
+	info.Offset += n
+	if err := s.writeInfo(info); err != nil {
+		return 0, err
+	}
+	return info.Offset, nil
+}
+
+func (s *FileStore) Reader(ctx context.Context, id string) (io.ReadCloser, error) {
+	info, err := s.readInfo(id)
+	if err != nil {
+		return nil, err
+	}
+	if !uploadFromInfo(info).Complete() {
+		return nil, errors.New("tus: upload is not yet complete")
+	}
+	f, err := os.Open(s.dataPath(id))
+	if err != nil {
+		return nil, errors.Wrap(err, "tus: failed to open completed upload")
+	}
+	return f, nil
+}
+
+func (s *FileStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	delete(s.lock, id)
+	s.mu.Unlock()
+
+	if err := os.RemoveAll(s.dir(id)); err != nil {
+		return errors.Wrap(err, "tus: failed to delete upload")
+	}
+	return nil
+}
+
+func (s *FileStore) Expired(ctx context.Context, now time.Time) ([]Upload, error) {
+	entries, err := os.ReadDir(s.baseDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "tus: failed to list uploads")
+	}
+
+	var expired []Upload
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		info, err := s.readInfo(entry.Name())
+		if err != nil {
+			continue // racing with Delete, or a corrupt/partial directory; skip rather than fail the sweep
+		}
+		if info.ExpiresAt.Before(now) {
+			expired = append(expired, uploadFromInfo(info))
+		}
+	}
+	return expired, nil
+}
+
+func uploadFromInfo(info fileInfo) Upload {
+	return Upload{
+		ID:        info.ID,
+		Size:      info.Size,
+		Offset:    info.Offset,
+		Metadata:  info.Metadata,
+		ExpiresAt: info.ExpiresAt,
+	}
+}
@@ -0,0 +1,39 @@
+package tus
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultGCInterval is how often StartGC sweeps for expired uploads.
+const DefaultGCInterval = 10 * time.Minute
+
+// RunGC deletes every upload in store that expired before now.
+func RunGC(ctx context.Context, store Store, log logrus.FieldLogger) {
+	expired, err := store.Expired(ctx, time.Now())
+	if err != nil {
+		log.WithError(err).Warn("failed to list expired uploads")
+		return
+	}
+	for _, upload := range expired {
+		if err := store.Delete(ctx, upload.ID); err != nil {
+			log.WithError(err).WithField("upload_id", upload.ID).Warn("failed to delete expired upload")
+		}
+	}
+}
+
+// StartGC runs RunGC on a ticker until ctx is canceled.
+func StartGC(ctx context.Context, store Store, interval time.Duration, log logrus.FieldLogger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			RunGC(ctx, store, log)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package tus
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Handler implements the TUS 1.0.0 core protocol plus the creation, expiration, and checksum
+// extensions, routed at a single prefix (e.g. "/uploads/"): POST creates an upload, HEAD reports
+// its current offset, PATCH appends a chunk.
+type Handler struct {
+	Store Store
+	// MaxSize caps Upload-Length on creation; zero means unbounded.
+	MaxSize int64
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", ResumableVersion)
+
+	switch r.Method {
+	case http.MethodPost:
+		h.create(w, r)
+	case http.MethodHead:
+		h.head(w, r)
+	case http.MethodPatch:
+		h.patch(w, r)
+	case http.MethodOptions:
+		w.Header().Set("Tus-Version", ResumableVersion)
+		w.Header().Set("Tus-Extension", Extensions)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	size, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || size < 0 {
+		http.Error(w, "missing or invalid Upload-Length", http.StatusBadRequest)
+		return
+	}
+	if h.MaxSize > 0 && size > h.MaxSize {
+		http.Error(w, "Upload-Length exceeds the maximum allowed size", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	metadata, err := parseUploadMetadata(r.Header.Get("Upload-Metadata"))
+	if err != nil {
+		http.Error(w, "invalid Upload-Metadata", http.StatusBadRequest)
+		return
+	}
+
+	upload, err := h.Store.Create(r.Context(), size, metadata)
+	if err != nil {
+		http.Error(w, "failed to create upload", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", uploadLocation(r, upload.ID))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *Handler) head(w http.ResponseWriter, r *http.Request) {
+	upload, err := h.Store.Get(r.Context(), mux.Vars(r)["id"])
+	if err == ErrNotFound {
+		http.Error(w, "upload not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "failed to look up upload", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(upload.Size, 10))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) patch(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if r.Header.Get("Content-Type") != "application/offset+octet-stream" {
+		http.Error(w, "unsupported Content-Type", http.StatusUnsupportedMediaType)
+		return
+	}
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		http.Error(w, "missing or invalid Upload-Offset", http.StatusBadRequest)
+		return
+	}
+
+	body := io.Reader(r.Body)
+	if checksum := r.Header.Get("Upload-Checksum"); checksum != "" {
+		verified, err := verifyingReader(body, checksum)
+		if err != nil {
+			http.Error(w, "unsupported Upload-Checksum algorithm", http.StatusBadRequest)
+			return
+		}
+		body = verified
+	}
+
+	newOffset, err := h.Store.WriteChunk(r.Context(), id, offset, body)
+	switch err {
+	case nil:
+	case ErrNotFound:
+		http.Error(w, "upload not found", http.StatusNotFound)
+		return
+	case ErrOffsetMismatch:
+		http.Error(w, "Upload-Offset does not match the upload's current offset", http.StatusConflict)
+		return
+	case ErrChecksumMismatch:
+		// 460 Checksum Mismatch, per the TUS checksum extension.
+		http.Error(w, "checksum mismatch", 460)
+		return
+	default:
+		http.Error(w, "failed to write upload chunk", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(newOffset, 10))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// uploadLocation builds the absolute URL a client should PATCH to continue id's upload.
+func uploadLocation(r *http.Request, id string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	base := strings.TrimSuffix(r.URL.Path, "/")
+	return scheme + "://" + r.Host + base + "/" + id
+}
+
+// parseUploadMetadata decodes a Upload-Metadata header: comma-separated "key base64(value)" pairs.
+func parseUploadMetadata(header string) (map[string]string, error) {
+	if header == "" {
+		return nil, nil
+	}
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), " ", 2)
+		key := parts[0]
+		if key == "" {
+			continue
+		}
+		value := ""
+		if len(parts) == 2 {
+			decoded, err := base64.StdEncoding.DecodeString(parts[1])
+			if err != nil {
+				return nil, err
+			}
+			value = string(decoded)
+		}
+		metadata[key] = value
+	}
+	return metadata, nil
+}
+
+// verifyingReader wraps body in a reader that, once fully consumed, verifies its sha256 against
+// checksum (an Upload-Checksum header value, "sha256 <base64-digest>"). The mismatch only
+// surfaces on the read that reaches EOF, matching how Store.WriteChunk streams the body in one
+// pass.
+func verifyingReader(body io.Reader, checksum string) (io.Reader, error) {
+	parts := strings.SplitN(checksum, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "sha256") {
+		return nil, errUnsupportedChecksumAlgorithm
+	}
+	want, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	return &checksumReader{r: body, want: want, h: sha256.New()}, nil
+}
+
+var errUnsupportedChecksumAlgorithm = errUnsupportedChecksum("tus: unsupported checksum algorithm")
+
+type errUnsupportedChecksum string
+
+func (e errUnsupportedChecksum) Error() string { return string(e) }
+
+// checksumReader hashes every byte as it's read and, on EOF, compares the running hash against
+// want, returning ErrChecksumMismatch instead of io.EOF if they differ.
+type checksumReader struct {
+	r    io.Reader
+	want []byte
+	h    hash.Hash
+}
+
+func (c *checksumReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if n > 0 {
+		c.h.Write(p[:n])
+	}
+	if err == io.EOF && !bytes.Equal(c.h.Sum(nil), c.want) {
+		return n, ErrChecksumMismatch
+	}
+	return n, err
+}
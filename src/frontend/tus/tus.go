@@ -0,0 +1,64 @@
+// Package tus implements the upload-creation, expiration, and checksum extensions of the TUS
+// 1.0.0 resumable upload protocol (https://tus.io/protocols/resumer.html#core-protocol). It backs
+// large/flaky uploads (e.g. tryOnHandler's base_image) that shouldn't be read fully into memory or
+// restarted from scratch after a dropped mobile connection.
+//
+// Store is the pluggable persistence boundary; FileStore is the only implementation here, but an
+// S3-compatible one can satisfy the same interface without touching Handler.
+package tus
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// ResumableVersion and Extensions are advertised in every response's Tus-Resumable/Tus-Extension
+// headers.
+const (
+	ResumableVersion = "1.0.0"
+	Extensions       = "creation,expiration,checksum"
+)
+
+// Upload is one in-progress or completed resumable upload.
+type Upload struct {
+	ID        string
+	Size      int64 // total expected size, from the creation request's Upload-Length
+	Offset    int64 // bytes received so far
+	Metadata  map[string]string
+	ExpiresAt time.Time
+}
+
+// Complete reports whether every byte of the upload has been received.
+func (u Upload) Complete() bool {
+	return u.Offset >= u.Size
+}
+
+// Store is the persistence boundary for resumable uploads.
+type Store interface {
+	// Create reserves a new upload of the given total size and returns its opaque ID.
+	Create(ctx context.Context, size int64, metadata map[string]string) (Upload, error)
+	// Get returns an upload's current state, or ErrNotFound if id is unknown or expired.
+	Get(ctx context.Context, id string) (Upload, error)
+	// WriteChunk appends r's bytes to id's upload starting at offset, returning the new offset.
+	// It errors with ErrOffsetMismatch if offset doesn't match the upload's current offset.
+	WriteChunk(ctx context.Context, id string, offset int64, r io.Reader) (newOffset int64, err error)
+	// Reader opens the completed upload's assembled bytes for streaming elsewhere. The caller must
+	// Close it. Errors if the upload isn't yet complete.
+	Reader(ctx context.Context, id string) (io.ReadCloser, error)
+	// Delete removes an upload and its data, e.g. once consumed or expired.
+	Delete(ctx context.Context, id string) error
+	// Expired lists uploads whose ExpiresAt is before now, for GC.
+	Expired(ctx context.Context, now time.Time) ([]Upload, error)
+}
+
+// sentinel errors returned by Store implementations.
+type storeError string
+
+func (e storeError) Error() string { return string(e) }
+
+const (
+	ErrNotFound         = storeError("tus: upload not found")
+	ErrOffsetMismatch   = storeError("tus: offset mismatch")
+	ErrChecksumMismatch = storeError("tus: checksum mismatch")
+)
@@ -0,0 +1,156 @@
+package tus
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func newTestHandler(t *testing.T) (*Handler, *FileStore) {
+	t.Helper()
+	store, err := NewFileStore(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	h := &Handler{Store: store}
+	return h, store
+}
+
+func router(h *Handler) *mux.Router {
+	r := mux.NewRouter()
+	r.Handle("/uploads/", h)
+	r.Handle("/uploads/{id}", h)
+	return r
+}
+
+func TestCreateHeadPatchRoundTrip(t *testing.T) {
+	h, _ := newTestHandler(t)
+	r := router(h)
+
+	content := []byte("the quick brown fox jumps over the lazy dog")
+
+	createReq := httptest.NewRequest(http.MethodPost, "/uploads/", nil)
+	createReq.Header.Set("Upload-Length", strconv.Itoa(len(content)))
+	createReq.Header.Set("Upload-Metadata", "filename "+base64.StdEncoding.EncodeToString([]byte("dog.jpg")))
+	createRec := httptest.NewRecorder()
+	r.ServeHTTP(createRec, createReq)
+	if createRec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", createRec.Code, createRec.Body.String())
+	}
+	location := createRec.Header().Get("Location")
+	if location == "" {
+		t.Fatal("expected a Location header")
+	}
+
+	headReq := httptest.NewRequest(http.MethodHead, extractPath(location), nil)
+	headRec := httptest.NewRecorder()
+	r.ServeHTTP(headRec, headReq)
+	if headRec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", headRec.Code)
+	}
+	if headRec.Header().Get("Upload-Offset") != "0" {
+		t.Fatalf("expected offset 0 on a fresh upload, got %q", headRec.Header().Get("Upload-Offset"))
+	}
+
+	patchReq := httptest.NewRequest(http.MethodPatch, extractPath(location), bytes.NewReader(content))
+	patchReq.Header.Set("Content-Type", "application/offset+octet-stream")
+	patchReq.Header.Set("Upload-Offset", "0")
+	patchRec := httptest.NewRecorder()
+	r.ServeHTTP(patchRec, patchReq)
+	if patchRec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", patchRec.Code, patchRec.Body.String())
+	}
+	if patchRec.Header().Get("Upload-Offset") != strconv.Itoa(len(content)) {
+		t.Fatalf("expected offset %d after the chunk, got %q", len(content), patchRec.Header().Get("Upload-Offset"))
+	}
+}
+
+func TestPatchRejectsOffsetMismatch(t *testing.T) {
+	h, store := newTestHandler(t)
+	r := router(h)
+
+	upload, err := store.Create(context.Background(), 10, nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	patchReq := httptest.NewRequest(http.MethodPatch, "/uploads/"+upload.ID, bytes.NewReader([]byte("abc")))
+	patchReq.Header.Set("Content-Type", "application/offset+octet-stream")
+	patchReq.Header.Set("Upload-Offset", "5") // wrong: the upload is still at offset 0
+	patchRec := httptest.NewRecorder()
+	r.ServeHTTP(patchRec, patchReq)
+	if patchRec.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", patchRec.Code)
+	}
+}
+
+func TestPatchRejectsChecksumMismatch(t *testing.T) {
+	h, store := newTestHandler(t)
+	r := router(h)
+
+	content := []byte("checksum me")
+	upload, err := store.Create(context.Background(), int64(len(content)), nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	wrongSum := sha256.Sum256([]byte("not the content"))
+	patchReq := httptest.NewRequest(http.MethodPatch, "/uploads/"+upload.ID, bytes.NewReader(content))
+	patchReq.Header.Set("Content-Type", "application/offset+octet-stream")
+	patchReq.Header.Set("Upload-Offset", "0")
+	patchReq.Header.Set("Upload-Checksum", "sha256 "+base64.StdEncoding.EncodeToString(wrongSum[:]))
+	patchRec := httptest.NewRecorder()
+	r.ServeHTTP(patchRec, patchReq)
+	if patchRec.Code != 460 {
+		t.Fatalf("expected 460 checksum mismatch, got %d", patchRec.Code)
+	}
+}
+
+func TestReaderErrorsUntilUploadIsComplete(t *testing.T) {
+	store, err := NewFileStore(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	upload, err := store.Create(context.Background(), 5, nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if _, err := store.Reader(context.Background(), upload.ID); err == nil {
+		t.Fatal("expected Reader to error before the upload is complete")
+	}
+
+	if _, err := store.WriteChunk(context.Background(), upload.ID, 0, bytes.NewReader([]byte("hello"))); err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+
+	rc, err := store.Reader(context.Background(), upload.ID)
+	if err != nil {
+		t.Fatalf("Reader: %v", err)
+	}
+	defer rc.Close()
+	got := make([]byte, 5)
+	if _, err := rc.Read(got); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if string(got) != "hello" {
+		t.Fatalf("expected %q, got %q", "hello", string(got))
+	}
+}
+
+func extractPath(absoluteURL string) string {
+	u, err := url.Parse(absoluteURL)
+	if err != nil {
+		return absoluteURL
+	}
+	return u.Path
+}
@@ -0,0 +1,188 @@
+package video
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Box is one ISO/IEC 14496-12 ("MP4") box: an 8 (or 16, for a 64-bit size) byte header followed
+// by Size-headerLen bytes of body, which for a container box (moov, trak, ...) is itself a
+// sequence of child boxes.
+type Box struct {
+	Type   string
+	Size   int64 // total box size, including the header
+	Offset int64 // offset of the header's first byte within the stream
+	Body   int64 // offset of the first body byte within the stream
+}
+
+// bodyLen is how many bytes of this box's body follow Body.
+func (b Box) bodyLen() int64 { return b.Size - (b.Body - b.Offset) }
+
+// ReadBoxes reads the sequence of top-level boxes in r, e.g. ftyp/moov/mdat for a progressive
+// MP4. It only reads headers, not box bodies, so it's cheap to run over a large file just to
+// validate its shape before committing to a transcode.
+func ReadBoxes(r io.ReadSeeker) ([]Box, error) {
+	var boxes []Box
+	offset, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, errors.Wrap(err, "video: failed to seek")
+	}
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, errors.Wrap(err, "video: failed to seek to end")
+	}
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return nil, errors.Wrap(err, "video: failed to seek")
+	}
+
+	for offset < end {
+		box, err := readBoxHeader(r, offset)
+		if err != nil {
+			return nil, err
+		}
+		if box.Size <= 0 {
+			return nil, errors.Errorf("video: box %q at offset %d has invalid size %d", box.Type, offset, box.Size)
+		}
+		boxes = append(boxes, box)
+		offset = box.Offset + box.Size
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return nil, errors.Wrap(err, "video: failed to seek past box")
+		}
+	}
+	return boxes, nil
+}
+
+func readBoxHeader(r io.Reader, offset int64) (Box, error) {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return Box{}, errors.Wrap(err, "video: failed to read box header")
+	}
+	size := int64(binary.BigEndian.Uint32(header[0:4]))
+	typ := string(header[4:8])
+	body := offset + 8
+
+	if size == 1 {
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return Box{}, errors.Wrap(err, "video: failed to read 64-bit box size")
+		}
+		size = int64(binary.BigEndian.Uint64(ext[:]))
+		body = offset + 16
+	}
+
+	return Box{Type: typ, Size: size, Offset: offset, Body: body}, nil
+}
+
+// FindChild scans parent's body for the first direct child box of the given type. parent must
+// have been read by ReadBoxes (or FindChild itself) against the same r.
+func FindChild(r io.ReadSeeker, parent Box, childType string) (*Box, error) {
+	if _, err := r.Seek(parent.Body, io.SeekStart); err != nil {
+		return nil, errors.Wrap(err, "video: failed to seek into box")
+	}
+	offset := parent.Body
+	end := parent.Body + parent.bodyLen()
+	for offset < end {
+		box, err := readBoxHeader(r, offset)
+		if err != nil {
+			return nil, err
+		}
+		if box.Type == childType {
+			return &box, nil
+		}
+		offset = box.Offset + box.Size
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return nil, errors.Wrap(err, "video: failed to seek past box")
+		}
+	}
+	return nil, nil
+}
+
+// MovieHeader is the subset of an 'mvhd' box Validate and the store need: enough to report the
+// source asset's duration.
+type MovieHeader struct {
+	Timescale uint32
+	Duration  uint64 // in Timescale units
+}
+
+// DurationSeconds converts Duration to seconds using Timescale.
+func (h MovieHeader) DurationSeconds() float64 {
+	if h.Timescale == 0 {
+		return 0
+	}
+	return float64(h.Duration) / float64(h.Timescale)
+}
+
+// ReadMovieHeader locates and parses the 'mvhd' box inside moov (as found by ReadBoxes).
+func ReadMovieHeader(r io.ReadSeeker, moov Box) (*MovieHeader, error) {
+	mvhd, err := FindChild(r, moov, "mvhd")
+	if err != nil {
+		return nil, err
+	}
+	if mvhd == nil {
+		return nil, errors.New("video: moov box has no mvhd child")
+	}
+
+	if _, err := r.Seek(mvhd.Body, io.SeekStart); err != nil {
+		return nil, errors.Wrap(err, "video: failed to seek to mvhd body")
+	}
+	var versionAndFlags [4]byte
+	if _, err := io.ReadFull(r, versionAndFlags[:]); err != nil {
+		return nil, errors.Wrap(err, "video: failed to read mvhd version/flags")
+	}
+
+	if versionAndFlags[0] == 1 {
+		// version 1: creation/modification time are 64-bit.
+		var rest [28]byte
+		if _, err := io.ReadFull(r, rest[:]); err != nil {
+			return nil, errors.Wrap(err, "video: failed to read mvhd body (v1)")
+		}
+		return &MovieHeader{
+			Timescale: binary.BigEndian.Uint32(rest[16:20]),
+			Duration:  binary.BigEndian.Uint64(rest[20:28]),
+		}, nil
+	}
+
+	// version 0: creation/modification time, timescale, and duration are all 32-bit.
+	var rest [16]byte
+	if _, err := io.ReadFull(r, rest[:]); err != nil {
+		return nil, errors.Wrap(err, "video: failed to read mvhd body (v0)")
+	}
+	return &MovieHeader{
+		Timescale: binary.BigEndian.Uint32(rest[8:12]),
+		Duration:  uint64(binary.BigEndian.Uint32(rest[12:16])),
+	}, nil
+}
+
+// Validate checks that r looks like a well-formed progressive MP4 (has an ftyp, a moov with an
+// mvhd, and an mdat), failing fast on garbage input before a transcode is attempted.
+func Validate(r io.ReadSeeker) (*MovieHeader, error) {
+	boxes, err := ReadBoxes(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var ftyp, moov, mdat *Box
+	for i := range boxes {
+		switch boxes[i].Type {
+		case "ftyp":
+			ftyp = &boxes[i]
+		case "moov":
+			moov = &boxes[i]
+		case "mdat":
+			mdat = &boxes[i]
+		}
+	}
+	if ftyp == nil {
+		return nil, errors.New("video: missing ftyp box")
+	}
+	if moov == nil {
+		return nil, errors.New("video: missing moov box")
+	}
+	if mdat == nil {
+		return nil, errors.New("video: missing mdat box")
+	}
+
+	return ReadMovieHeader(r, *moov)
+}
@@ -0,0 +1,82 @@
+package video
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// box encodes a minimal box: 4-byte size, 4-byte type, then body.
+func box(typ string, body []byte) []byte {
+	var b bytes.Buffer
+	size := make([]byte, 4)
+	binary.BigEndian.PutUint32(size, uint32(8+len(body)))
+	b.Write(size)
+	b.WriteString(typ)
+	b.Write(body)
+	return b.Bytes()
+}
+
+// buildMinimalMP4 assembles ftyp + moov{mvhd} + mdat, matching the byte layout box.go expects.
+func buildMinimalMP4(timescale, duration uint32) []byte {
+	mvhdBody := make([]byte, 20)
+	// version/flags (4 bytes), creation time (4), modification time (4), then timescale and duration.
+	binary.BigEndian.PutUint32(mvhdBody[12:16], timescale)
+	binary.BigEndian.PutUint32(mvhdBody[16:20], duration)
+	mvhd := box("mvhd", mvhdBody)
+
+	moov := box("moov", mvhd)
+	ftyp := box("ftyp", []byte("isom\x00\x00\x00\x00isom"))
+	mdat := box("mdat", []byte("fake-video-bytes"))
+
+	var all bytes.Buffer
+	all.Write(ftyp)
+	all.Write(moov)
+	all.Write(mdat)
+	return all.Bytes()
+}
+
+func TestReadBoxesFindsTopLevelBoxes(t *testing.T) {
+	data := buildMinimalMP4(600, 1200)
+	r := bytes.NewReader(data)
+
+	boxes, err := ReadBoxes(r)
+	if err != nil {
+		t.Fatalf("ReadBoxes: %v", err)
+	}
+	if len(boxes) != 3 {
+		t.Fatalf("expected 3 top-level boxes, got %d", len(boxes))
+	}
+	wantTypes := []string{"ftyp", "moov", "mdat"}
+	for i, box := range boxes {
+		if box.Type != wantTypes[i] {
+			t.Errorf("box %d: got type %q, want %q", i, box.Type, wantTypes[i])
+		}
+	}
+}
+
+func TestValidateExtractsMovieHeaderDuration(t *testing.T) {
+	data := buildMinimalMP4(600, 1200)
+	r := bytes.NewReader(data)
+
+	header, err := Validate(r)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if header.Timescale != 600 || header.Duration != 1200 {
+		t.Fatalf("unexpected header %+v", header)
+	}
+	if got, want := header.DurationSeconds(), 2.0; got != want {
+		t.Fatalf("DurationSeconds() = %v, want %v", got, want)
+	}
+}
+
+func TestValidateRejectsMissingMoov(t *testing.T) {
+	var data bytes.Buffer
+	data.Write(box("ftyp", []byte("isom")))
+	data.Write(box("mdat", []byte("bytes")))
+
+	if _, err := Validate(bytes.NewReader(data.Bytes())); err == nil {
+		t.Fatal("expected an error for an MP4 with no moov box")
+	}
+}
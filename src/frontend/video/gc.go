@@ -0,0 +1,99 @@
+package video
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultGCInterval and DefaultMaxCacheBytes bound the background eviction sweep, overridable via
+// VIDEO_CACHE_GC_INTERVAL and VIDEO_CACHE_MAX_BYTES.
+const (
+	DefaultGCInterval    = 10 * time.Minute
+	DefaultMaxCacheBytes = 20 << 30 // 20GiB
+)
+
+// RunGC evicts least-recently-viewed job directories until the cache is back under maxBytes. It's
+// meant to be run periodically on its own goroutine (see StartGC).
+func (s *Store) RunGC(maxBytes int64, log logrus.FieldLogger) {
+	entries, err := os.ReadDir(s.baseDir)
+	if err != nil {
+		log.WithError(err).Warn("video: gc failed to list cache dir")
+		return
+	}
+
+	type job struct {
+		id         string
+		size       int64
+		lastViewed time.Time
+	}
+	var jobs []job
+	var total int64
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		size := dirSize(filepath.Join(s.baseDir, entry.Name()))
+		total += size
+		jobs = append(jobs, job{id: entry.Name(), size: size, lastViewed: s.lastViewedAt(entry.Name())})
+	}
+	if total <= maxBytes {
+		return
+	}
+
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].lastViewed.Before(jobs[j].lastViewed) })
+	for _, j := range jobs {
+		if total <= maxBytes {
+			break
+		}
+		if err := os.RemoveAll(filepath.Join(s.baseDir, j.id)); err != nil {
+			log.WithError(err).WithField("job_id", j.id).Warn("video: gc failed to evict job")
+			continue
+		}
+		s.forget(j.id)
+		total -= j.size
+		log.WithField("job_id", j.id).Info("video: gc evicted least-recently-viewed rendition cache")
+	}
+}
+
+// StartGC runs RunGC on a ticker until ctx is canceled.
+func (s *Store) StartGC(ctx context.Context, interval time.Duration, maxBytes int64, log logrus.FieldLogger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.RunGC(maxBytes, log)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Store) lastViewedAt(jobID string) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastViewed[jobID]
+}
+
+func (s *Store) forget(jobID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.lastViewed, jobID)
+}
+
+func dirSize(dir string) int64 {
+	var total int64
+	_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		total += info.Size()
+		return nil
+	})
+	return total
+}
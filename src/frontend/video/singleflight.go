@@ -0,0 +1,45 @@
+package video
+
+import "sync"
+
+// singleflight dedupes concurrent callers asking for the same key, e.g. two browser tabs hitting
+// serveVideoHandler for the same job before its renditions exist: the first caller runs fn; every
+// other caller blocks and receives the same result instead of starting a redundant transcode.
+type singleflight struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+type call struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+}
+
+func newSingleflight() *singleflight {
+	return &singleflight{calls: make(map[string]*call)}
+}
+
+// Do runs fn for key, or waits for and returns the in-flight call's result if one is already
+// running.
+func (s *singleflight) Do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	s.mu.Lock()
+	if c, ok := s.calls[key]; ok {
+		s.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err
+	}
+	c := &call{}
+	c.wg.Add(1)
+	s.calls[key] = c
+	s.mu.Unlock()
+
+	c.val, c.err = fn()
+	c.wg.Done()
+
+	s.mu.Lock()
+	delete(s.calls, key)
+	s.mu.Unlock()
+
+	return c.val, c.err
+}
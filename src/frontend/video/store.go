@@ -0,0 +1,137 @@
+// Package video lazily transcodes generated ad videos into an HLS rendition ladder and serves
+// them (progressive MP4 with Range support, or the HLS master playlist) with on-disk caching
+// keyed by job ID. See store.go for the cache, transcode.go for the ffmpeg pipeline, box.go for
+// the MP4 box parser used to validate a source file before transcoding, and gc.go for eviction.
+package video
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FetchSource retrieves the original source MP4 for jobID, e.g. from the video-generation
+// service. The caller owns closing the returned ReadCloser.
+type FetchSource func(ctx context.Context) (io.ReadCloser, error)
+
+// Store caches, on disk, the source MP4 and its transcoded HLS renditions for each job ID,
+// transcoding lazily (single-flighted per job so concurrent requests share one transcode) and
+// tracking last-viewed times for gc.go's eviction sweep.
+type Store struct {
+	baseDir    string
+	transcoder *Transcoder
+	// toHLS performs the actual transcode; defaults to transcoder.ToHLS but is swappable in
+	// tests so they don't need a real ffmpeg binary on PATH.
+	toHLS func(ctx context.Context, srcPath, outDir string) error
+	sf    *singleflight
+
+	mu         sync.Mutex
+	lastViewed map[string]time.Time
+}
+
+// NewStore builds a Store rooted at baseDir (created if it doesn't exist), transcoding with t.
+func NewStore(baseDir string, t *Transcoder) (*Store, error) {
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, errors.Wrap(err, "video: failed to create cache dir")
+	}
+	s := &Store{
+		baseDir:    baseDir,
+		transcoder: t,
+		sf:         newSingleflight(),
+		lastViewed: make(map[string]time.Time),
+	}
+	if t != nil {
+		s.toHLS = t.ToHLS
+	}
+	return s, nil
+}
+
+func (s *Store) jobDir(jobID string) string {
+	return filepath.Join(s.baseDir, jobID)
+}
+
+// SourcePath is where jobID's original MP4 lives once Ensure has run.
+func (s *Store) SourcePath(jobID string) string {
+	return filepath.Join(s.jobDir(jobID), "source.mp4")
+}
+
+// MasterPlaylistPath is where jobID's HLS master playlist lives once Ensure has run.
+func (s *Store) MasterPlaylistPath(jobID string) string {
+	return filepath.Join(s.jobDir(jobID), "master.m3u8")
+}
+
+// Ensure makes sure jobID's source MP4 and HLS renditions exist on disk, fetching the source via
+// fetch and transcoding only on first access (or if a prior attempt left the cache incomplete).
+// Concurrent callers for the same jobID share one fetch+transcode.
+func (s *Store) Ensure(ctx context.Context, jobID string, fetch FetchSource) error {
+	if s.isComplete(jobID) {
+		s.touch(jobID)
+		return nil
+	}
+
+	_, err := s.sf.Do(jobID, func() (interface{}, error) {
+		if s.isComplete(jobID) {
+			return nil, nil
+		}
+		return nil, s.ingest(ctx, jobID, fetch)
+	})
+	if err == nil {
+		s.touch(jobID)
+	}
+	return err
+}
+
+func (s *Store) isComplete(jobID string) bool {
+	_, err := os.Stat(s.MasterPlaylistPath(jobID))
+	return err == nil
+}
+
+func (s *Store) ingest(ctx context.Context, jobID string, fetch FetchSource) error {
+	dir := s.jobDir(jobID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return errors.Wrap(err, "video: failed to create job cache dir")
+	}
+
+	body, err := fetch(ctx)
+	if err != nil {
+		return errors.Wrap(err, "video: failed to fetch source video")
+	}
+	defer body.Close()
+
+	srcPath := s.SourcePath(jobID)
+	f, err := os.Create(srcPath)
+	if err != nil {
+		return errors.Wrap(err, "video: failed to create source file")
+	}
+	if _, err := io.Copy(f, body); err != nil {
+		f.Close()
+		return errors.Wrap(err, "video: failed to write source file")
+	}
+	f.Close()
+
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return errors.Wrap(err, "video: failed to reopen source file")
+	}
+	_, err = Validate(src)
+	src.Close()
+	if err != nil {
+		return errors.Wrap(err, "video: source file failed validation")
+	}
+
+	if err := s.toHLS(ctx, srcPath, dir); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Store) touch(jobID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastViewed[jobID] = time.Now()
+}
@@ -0,0 +1,101 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeTranscoder stands in for ffmpeg in tests: it just writes the files ToHLS's real
+// implementation would, without actually encoding anything.
+type fakeTranscoder struct {
+	calls int32
+}
+
+func (f *fakeTranscoder) ToHLS(ctx context.Context, srcPath, outDir string) error {
+	atomic.AddInt32(&f.calls, 1)
+	time.Sleep(10 * time.Millisecond) // exercise the single-flight window
+	return writeMasterPlaylist(outDir, DefaultLadder)
+}
+
+// newTestStore builds a Store whose transcode step is faked, so tests don't need a real ffmpeg
+// binary on PATH.
+func newTestStore(t *testing.T, fake *fakeTranscoder) *Store {
+	t.Helper()
+	s, err := NewStore(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	s.toHLS = fake.ToHLS
+	return s
+}
+
+func TestStoreEnsureTranscodesOnceForConcurrentCallers(t *testing.T) {
+	fake := &fakeTranscoder{}
+	s := newTestStore(t, fake)
+
+	fetch := func(ctx context.Context) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buildMinimalMP4(600, 1200))), nil
+	}
+
+	const n = 5
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			errs <- s.Ensure(context.Background(), "job-1", fetch)
+		}()
+	}
+	for i := 0; i < n; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("Ensure: %v", err)
+		}
+	}
+
+	if got := atomic.LoadInt32(&fake.calls); got != 1 {
+		t.Fatalf("expected exactly one transcode for concurrent callers, got %d", got)
+	}
+	if _, err := os.Stat(s.MasterPlaylistPath("job-1")); err != nil {
+		t.Fatalf("expected a master playlist on disk: %v", err)
+	}
+}
+
+func TestStoreEnsureSkipsTranscodeOnceCached(t *testing.T) {
+	fake := &fakeTranscoder{}
+	s := newTestStore(t, fake)
+
+	fetch := func(ctx context.Context) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buildMinimalMP4(600, 1200))), nil
+	}
+
+	if err := s.Ensure(context.Background(), "job-1", fetch); err != nil {
+		t.Fatalf("Ensure: %v", err)
+	}
+	if err := s.Ensure(context.Background(), "job-1", fetch); err != nil {
+		t.Fatalf("Ensure (cached): %v", err)
+	}
+
+	if got := atomic.LoadInt32(&fake.calls); got != 1 {
+		t.Fatalf("expected the second Ensure to skip transcoding, got %d calls", got)
+	}
+}
+
+func TestStoreEnsureRejectsInvalidSource(t *testing.T) {
+	fake := &fakeTranscoder{}
+	s := newTestStore(t, fake)
+
+	fetch := func(ctx context.Context) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader([]byte("not an mp4"))), nil
+	}
+
+	if err := s.Ensure(context.Background(), "job-1", fetch); err == nil {
+		t.Fatal("expected an error for an invalid source file")
+	}
+	if _, err := os.Stat(filepath.Join(s.baseDir, "job-1", "master.m3u8")); err == nil {
+		t.Fatal("expected no master playlist to be written for an invalid source")
+	}
+}
@@ -0,0 +1,98 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// Rendition is one rung of the HLS bitrate ladder.
+type Rendition struct {
+	Name        string // also the output subdirectory name, e.g. "360p"
+	Height      int
+	BitrateKbps int
+}
+
+// DefaultLadder is the 360p/720p/1080p ladder the request asks for.
+var DefaultLadder = []Rendition{
+	{Name: "360p", Height: 360, BitrateKbps: 800},
+	{Name: "720p", Height: 720, BitrateKbps: 2800},
+	{Name: "1080p", Height: 1080, BitrateKbps: 5000},
+}
+
+// Transcoder drives ffmpeg to turn a source MP4 into an HLS rendition ladder plus a master
+// playlist. ffmpeg does the actual encoding; this package's job is orchestration (which
+// renditions, where they land on disk, when re-transcoding is avoided) and serving.
+type Transcoder struct {
+	// FFmpegPath is the ffmpeg binary to exec, overridable (VIDEO_FFMPEG_PATH) for environments
+	// where it isn't on PATH.
+	FFmpegPath string
+	Ladder     []Rendition
+}
+
+// NewTranscoder builds a Transcoder using DefaultLadder and the given ffmpeg binary path.
+func NewTranscoder(ffmpegPath string) *Transcoder {
+	return &Transcoder{FFmpegPath: ffmpegPath, Ladder: DefaultLadder}
+}
+
+// ToHLS transcodes srcPath into outDir/<rendition>/index.m3u8 for every rung of t.Ladder, then
+// writes outDir/master.m3u8 referencing all of them.
+func (t *Transcoder) ToHLS(ctx context.Context, srcPath, outDir string) error {
+	for _, rendition := range t.Ladder {
+		renditionDir := filepath.Join(outDir, rendition.Name)
+		if err := os.MkdirAll(renditionDir, 0o755); err != nil {
+			return errors.Wrapf(err, "video: failed to create rendition dir for %s", rendition.Name)
+		}
+		if err := t.transcodeRendition(ctx, srcPath, renditionDir, rendition); err != nil {
+			return errors.Wrapf(err, "video: failed to transcode rendition %s", rendition.Name)
+		}
+	}
+	return writeMasterPlaylist(outDir, t.Ladder)
+}
+
+func (t *Transcoder) transcodeRendition(ctx context.Context, srcPath, renditionDir string, rendition Rendition) error {
+	playlist := filepath.Join(renditionDir, "index.m3u8")
+	segmentPattern := filepath.Join(renditionDir, "segment%03d.ts")
+
+	//nolint:gosec // srcPath/renditionDir are server-controlled (job cache dir + upstream fetch), not raw user input.
+	cmd := exec.CommandContext(ctx, t.FFmpegPath,
+		"-y",
+		"-i", srcPath,
+		"-vf", fmt.Sprintf("scale=-2:%d", rendition.Height),
+		"-b:v", fmt.Sprintf("%dk", rendition.BitrateKbps),
+		"-c:a", "aac",
+		"-hls_time", "6",
+		"-hls_playlist_type", "vod",
+		"-hls_segment_filename", segmentPattern,
+		playlist,
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "ffmpeg failed: %s", output)
+	}
+	return nil
+}
+
+// writeMasterPlaylist writes an HLS master playlist at outDir/master.m3u8, one #EXT-X-STREAM-INF
+// variant per rendition in ladder.
+func writeMasterPlaylist(outDir string, ladder []Rendition) error {
+	path := filepath.Join(outDir, "master.m3u8")
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "video: failed to create master playlist")
+	}
+	defer f.Close()
+
+	fmt.Fprintln(f, "#EXTM3U")
+	fmt.Fprintln(f, "#EXT-X-VERSION:3")
+	for _, rendition := range ladder {
+		fmt.Fprintf(f, "#EXT-X-STREAM-INF:BANDWIDTH=%d,RESOLUTION=%dx%d\n",
+			rendition.BitrateKbps*1000, rendition.Height*16/9, rendition.Height)
+		fmt.Fprintf(f, "%s/index.m3u8\n", rendition.Name)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package video
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteMasterPlaylistReferencesEveryRendition(t *testing.T) {
+	dir := t.TempDir()
+	ladder := []Rendition{
+		{Name: "360p", Height: 360, BitrateKbps: 800},
+		{Name: "720p", Height: 720, BitrateKbps: 2800},
+	}
+
+	if err := writeMasterPlaylist(dir, ladder); err != nil {
+		t.Fatalf("writeMasterPlaylist: %v", err)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, "master.m3u8"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	playlist := string(raw)
+	if !strings.HasPrefix(playlist, "#EXTM3U") {
+		t.Fatalf("expected playlist to start with #EXTM3U, got %q", playlist)
+	}
+	for _, rendition := range ladder {
+		if !strings.Contains(playlist, rendition.Name+"/index.m3u8") {
+			t.Errorf("expected playlist to reference %s/index.m3u8, got %q", rendition.Name, playlist)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Package videohub fans a single upstream SSE subscription for an async video-generation job out
+// to every local subscriber watching that job, so N browser tabs polling the same job_id share
+// one connection to the video-generation service instead of opening N.
+package videohub
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/sse"
+)
+
+// subscriberBuffer bounds how many frames a slow subscriber can fall behind before Hub starts
+// dropping frames for it rather than block the upstream decode loop for every other subscriber.
+const subscriberBuffer = 16
+
+// Open dials the upstream SSE stream for jobID. The returned ReadCloser is decoded frame-by-frame
+// and Closed when the last subscriber for jobID leaves or the stream ends.
+type Open func(ctx context.Context, jobID string) (io.ReadCloser, error)
+
+// Hub owns at most one upstream subscription per job_id.
+type Hub struct {
+	open Open
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+// New builds a Hub that dials upstream subscriptions via open.
+func New(open Open) *Hub {
+	return &Hub{open: open, jobs: make(map[string]*job)}
+}
+
+type job struct {
+	cancel context.CancelFunc
+
+	mu   sync.Mutex
+	subs map[chan sse.Event]struct{}
+}
+
+// Subscribe joins the fanout for jobID, starting the upstream subscription if this is the first
+// subscriber. The returned channel is closed when the upstream stream ends; call unsubscribe
+// (always, even after the channel closes) to release this subscriber's slot.
+func (h *Hub) Subscribe(jobID string) (events <-chan sse.Event, unsubscribe func()) {
+	h.mu.Lock()
+	j, ok := h.jobs[jobID]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		j = &job{cancel: cancel, subs: make(map[chan sse.Event]struct{})}
+		h.jobs[jobID] = j
+		go h.run(ctx, jobID, j)
+	}
+	ch := make(chan sse.Event, subscriberBuffer)
+	j.mu.Lock()
+	j.subs[ch] = struct{}{}
+	j.mu.Unlock()
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() { h.unsubscribe(jobID, j, ch) })
+	}
+}
+
+func (h *Hub) unsubscribe(jobID string, j *job, ch chan sse.Event) {
+	j.mu.Lock()
+	delete(j.subs, ch)
+	empty := len(j.subs) == 0
+	j.mu.Unlock()
+
+	if !empty {
+		return
+	}
+	h.mu.Lock()
+	if h.jobs[jobID] == j {
+		delete(h.jobs, jobID)
+	}
+	h.mu.Unlock()
+	j.cancel()
+}
+
+// run owns the single upstream subscription for jobID: it decodes frames and broadcasts each to
+// every current subscriber, closing all subscriber channels once the upstream ends.
+func (h *Hub) run(ctx context.Context, jobID string, j *job) {
+	defer h.closeAll(jobID, j)
+
+	body, err := h.open(ctx, jobID)
+	if err != nil {
+		return
+	}
+	defer body.Close()
+
+	dec := sse.NewDecoder(body)
+	for {
+		event, ok := dec.Next()
+		if !ok {
+			return
+		}
+		j.broadcast(event)
+	}
+}
+
+func (j *job) broadcast(event sse.Event) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for ch := range j.subs {
+		select {
+		case ch <- event:
+		default:
+			// Subscriber is too far behind; drop the frame rather than block every other
+			// subscriber on this job.
+		}
+	}
+}
+
+func (h *Hub) closeAll(jobID string, j *job) {
+	h.mu.Lock()
+	if h.jobs[jobID] == j {
+		delete(h.jobs, jobID)
+	}
+	h.mu.Unlock()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for ch := range j.subs {
+		close(ch)
+	}
+	j.subs = nil
+}
@@ -0,0 +1,91 @@
+package videohub
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/microservices-demo/src/frontend/sse"
+)
+
+func TestHubSharesOneUpstreamAcrossSubscribers(t *testing.T) {
+	var opens int32
+	h := New(func(ctx context.Context, jobID string) (io.ReadCloser, error) {
+		atomic.AddInt32(&opens, 1)
+		frame := sse.Event{Event: "progress", Data: `{"percent":50}`}
+		return io.NopCloser(strings.NewReader(string(frame.Encode()))), nil
+	})
+
+	ch1, unsub1 := h.Subscribe("job-1")
+	ch2, unsub2 := h.Subscribe("job-1")
+	defer unsub1()
+	defer unsub2()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	for _, ch := range []<-chan sse.Event{ch1, ch2} {
+		go func(ch <-chan sse.Event) {
+			defer wg.Done()
+			select {
+			case event, ok := <-ch:
+				if !ok {
+					t.Error("channel closed before delivering a frame")
+					return
+				}
+				if event.Event != "progress" {
+					t.Errorf("unexpected event type %q", event.Event)
+				}
+			case <-time.After(time.Second):
+				t.Error("timed out waiting for frame")
+			}
+		}(ch)
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&opens); got != 1 {
+		t.Fatalf("expected exactly one upstream open for two subscribers, got %d", got)
+	}
+}
+
+func TestHubClosesSubscriberChannelsWhenUpstreamEnds(t *testing.T) {
+	h := New(func(ctx context.Context, jobID string) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("")), nil
+	})
+
+	ch, unsub := h.Subscribe("job-1")
+	defer unsub()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("expected channel to close on an empty upstream stream")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for channel to close")
+	}
+}
+
+func TestHubStartsANewUpstreamAfterAllSubscribersLeave(t *testing.T) {
+	var opens int32
+	h := New(func(ctx context.Context, jobID string) (io.ReadCloser, error) {
+		atomic.AddInt32(&opens, 1)
+		return io.NopCloser(strings.NewReader("")), nil
+	})
+
+	_, unsub := h.Subscribe("job-1")
+	// Let the (empty) upstream stream end and the job clean itself out of the hub.
+	time.Sleep(50 * time.Millisecond)
+	unsub()
+
+	_, unsub2 := h.Subscribe("job-1")
+	defer unsub2()
+	time.Sleep(50 * time.Millisecond)
+
+	if got := atomic.LoadInt32(&opens); got != 2 {
+		t.Fatalf("expected a fresh upstream subscription after all subscribers left, got %d opens", got)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/catalog"
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/genproto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// productCatalog implements the ProductCatalogService and gRPC health-check RPCs by delegating to
+// a catalog.Repository (see the catalog package for the file/GCS/SQL backends, selected via
+// CATALOG_BACKEND). servingStatus backs the gRPC health check and is flipped to NOT_SERVING during
+// shutdown (see server.go) so load balancers stop routing before GracefulStop drains in-flight RPCs.
+type productCatalog struct {
+	pb.UnimplementedProductCatalogServiceServer
+
+	repo          catalog.Repository
+	servingStatus atomic.Int32 // healthpb.HealthCheckResponse_ServingStatus
+
+	// ready and reloading back the /readyz sidecar (see healthz.go): ready flips true once the
+	// first repository load succeeds, reloading is true for the window of an in-progress reload
+	// (file watch, URL poll, or manual SIGUSR1/2) so a probe can't land mid-swap.
+	ready     atomic.Bool
+	reloading atomic.Bool
+}
+
+func newProductCatalog(repo catalog.Repository) *productCatalog {
+	p := &productCatalog{repo: repo}
+	p.ready.Store(true) // repo constructors perform their own initial load before returning
+	return p
+}
+
+func (p *productCatalog) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	s := healthpb.HealthCheckResponse_ServingStatus(p.servingStatus.Load())
+	if s == healthpb.HealthCheckResponse_UNKNOWN {
+		s = healthpb.HealthCheckResponse_SERVING
+	}
+	return &healthpb.HealthCheckResponse{Status: s}, nil
+}
+
+// setServing flips the gRPC health status; called with false once during shutdown.
+func (p *productCatalog) setServing(serving bool) {
+	if serving {
+		p.servingStatus.Store(int32(healthpb.HealthCheckResponse_SERVING))
+	} else {
+		p.servingStatus.Store(int32(healthpb.HealthCheckResponse_NOT_SERVING))
+	}
+}
+
+func (p *productCatalog) Watch(req *healthpb.HealthCheckRequest, ws healthpb.Health_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "health check via Watch not implemented")
+}
+
+func (p *productCatalog) ListProducts(ctx context.Context, _ *pb.Empty) (*pb.ListProductsResponse, error) {
+	time.Sleep(extraLatency)
+	products, err := p.repo.List(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list products: %v", err)
+	}
+	return &pb.ListProductsResponse{Products: products}, nil
+}
+
+func (p *productCatalog) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.Product, error) {
+	time.Sleep(extraLatency)
+	product, err := p.repo.Get(ctx, req.GetId())
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "no product with ID %s", req.GetId())
+	}
+	return product, nil
+}
+
+func (p *productCatalog) SearchProducts(ctx context.Context, req *pb.SearchProductsRequest) (*pb.SearchProductsResponse, error) {
+	time.Sleep(extraLatency)
+	results, err := p.repo.Search(ctx, req.GetQuery())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to search products: %v", err)
+	}
+	return &pb.SearchProductsResponse{Results: results}, nil
+}
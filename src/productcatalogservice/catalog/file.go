@@ -0,0 +1,85 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/genproto"
+)
+
+// FileRepository serves the catalog from a local JSON file. Reload re-reads the file and swaps
+// the in-memory product list atomically, so List/Get/Search never block on a concurrent reload.
+type FileRepository struct {
+	path     string
+	products atomic.Pointer[[]*pb.Product]
+}
+
+// NewFileRepository loads path immediately and returns an error if that initial load fails.
+func NewFileRepository(path string) (*FileRepository, error) {
+	r := &FileRepository{path: path}
+	if err := r.Reload(context.Background()); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload implements catalog.Reloadable.
+func (r *FileRepository) Reload(ctx context.Context) error {
+	data, err := ioutil.ReadFile(r.path)
+	if err != nil {
+		return errors.Wrapf(err, "catalog: failed to read %s", r.path)
+	}
+
+	var parsed struct {
+		Products []*pb.Product `json:"products"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return errors.Wrapf(err, "catalog: failed to parse %s", r.path)
+	}
+	r.products.Store(&parsed.Products)
+	return nil
+}
+
+func (r *FileRepository) List(ctx context.Context) ([]*pb.Product, error) {
+	return r.snapshot(), nil
+}
+
+func (r *FileRepository) Get(ctx context.Context, id string) (*pb.Product, error) {
+	for _, product := range r.snapshot() {
+		if product.GetId() == id {
+			return product, nil
+		}
+	}
+	return nil, errors.Errorf("no product with ID %s", id)
+}
+
+func (r *FileRepository) Search(ctx context.Context, query string) ([]*pb.Product, error) {
+	return searchByNameOrDescription(r.snapshot(), query), nil
+}
+
+func (r *FileRepository) snapshot() []*pb.Product {
+	p := r.products.Load()
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// searchByNameOrDescription is shared by File and GCS, the two repositories that only have a
+// plain in-memory slice to search rather than a SQL LIKE/full-text index.
+func searchByNameOrDescription(products []*pb.Product, query string) []*pb.Product {
+	q := strings.ToLower(query)
+	var results []*pb.Product
+	for _, product := range products {
+		if strings.Contains(strings.ToLower(product.GetName()), q) ||
+			strings.Contains(strings.ToLower(product.GetDescription()), q) {
+			results = append(results, product)
+		}
+	}
+	return results
+}
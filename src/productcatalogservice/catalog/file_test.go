@@ -0,0 +1,100 @@
+package catalog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testCatalogJSON = `{
+  "products": [
+    {"id": "OLJCESPC7Z", "name": "Sunglasses", "description": "Add a modern touch to your outfits.", "categories": ["accessories"]},
+    {"id": "66VCHSJNUP", "name": "Tank Top", "description": "Perfectly cropped cotton tank.", "categories": ["clothing", "tops"]}
+  ]
+}`
+
+func writeTestCatalog(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "products.json")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("failed to write test catalog: %v", err)
+	}
+	return path
+}
+
+func TestFileRepositoryList(t *testing.T) {
+	path := writeTestCatalog(t, testCatalogJSON)
+	repo, err := NewFileRepository(path)
+	if err != nil {
+		t.Fatalf("NewFileRepository: %v", err)
+	}
+
+	products, err := repo.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(products) != 2 {
+		t.Fatalf("expected 2 products, got %d", len(products))
+	}
+}
+
+func TestFileRepositoryGet(t *testing.T) {
+	path := writeTestCatalog(t, testCatalogJSON)
+	repo, err := NewFileRepository(path)
+	if err != nil {
+		t.Fatalf("NewFileRepository: %v", err)
+	}
+
+	if _, err := repo.Get(context.Background(), "missing"); err == nil {
+		t.Fatal("expected error for missing product ID")
+	}
+
+	p, err := repo.Get(context.Background(), "OLJCESPC7Z")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if p.GetName() != "Sunglasses" {
+		t.Fatalf("expected Sunglasses, got %q", p.GetName())
+	}
+}
+
+func TestFileRepositorySearch(t *testing.T) {
+	path := writeTestCatalog(t, testCatalogJSON)
+	repo, err := NewFileRepository(path)
+	if err != nil {
+		t.Fatalf("NewFileRepository: %v", err)
+	}
+
+	results, err := repo.Search(context.Background(), "tank")
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 || results[0].GetId() != "66VCHSJNUP" {
+		t.Fatalf("expected single Tank Top match, got %+v", results)
+	}
+}
+
+func TestFileRepositoryReload(t *testing.T) {
+	path := writeTestCatalog(t, testCatalogJSON)
+	repo, err := NewFileRepository(path)
+	if err != nil {
+		t.Fatalf("NewFileRepository: %v", err)
+	}
+
+	updated := `{"products": [{"id": "NEW1", "name": "New Product", "description": "fresh"}]}`
+	if err := os.WriteFile(path, []byte(updated), 0o644); err != nil {
+		t.Fatalf("failed to rewrite catalog: %v", err)
+	}
+	if err := repo.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+
+	products, err := repo.List(context.Background())
+	if err != nil {
+		t.Fatalf("List after reload: %v", err)
+	}
+	if len(products) != 1 || products[0].GetId() != "NEW1" {
+		t.Fatalf("expected reload to replace catalog, got %+v", products)
+	}
+}
@@ -0,0 +1,83 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync/atomic"
+
+	"cloud.google.com/go/storage"
+	"github.com/pkg/errors"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/genproto"
+)
+
+// GCSRepository streams products.json from a Cloud Storage bucket. Reload re-downloads the
+// object; List/Get/Search serve from the last successfully downloaded snapshot so a slow or
+// failed download never blocks a request.
+type GCSRepository struct {
+	bucket, object string
+	client         *storage.Client
+	products       atomic.Pointer[[]*pb.Product]
+}
+
+// NewGCSRepository creates a storage client and performs the initial download of bucket/object.
+func NewGCSRepository(ctx context.Context, bucket, object string) (*GCSRepository, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "catalog: failed to create GCS client")
+	}
+	r := &GCSRepository{bucket: bucket, object: object, client: client}
+	if err := r.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload implements catalog.Reloadable.
+func (r *GCSRepository) Reload(ctx context.Context) error {
+	rc, err := r.client.Bucket(r.bucket).Object(r.object).NewReader(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "catalog: failed to open gs://%s/%s", r.bucket, r.object)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return errors.Wrapf(err, "catalog: failed to stream gs://%s/%s", r.bucket, r.object)
+	}
+
+	var parsed struct {
+		Products []*pb.Product `json:"products"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return errors.Wrapf(err, "catalog: failed to parse gs://%s/%s", r.bucket, r.object)
+	}
+	r.products.Store(&parsed.Products)
+	return nil
+}
+
+func (r *GCSRepository) List(ctx context.Context) ([]*pb.Product, error) {
+	return r.snapshot(), nil
+}
+
+func (r *GCSRepository) Get(ctx context.Context, id string) (*pb.Product, error) {
+	for _, product := range r.snapshot() {
+		if product.GetId() == id {
+			return product, nil
+		}
+	}
+	return nil, errors.Errorf("no product with ID %s", id)
+}
+
+func (r *GCSRepository) Search(ctx context.Context, query string) ([]*pb.Product, error) {
+	return searchByNameOrDescription(r.snapshot(), query), nil
+}
+
+func (r *GCSRepository) snapshot() []*pb.Product {
+	p := r.products.Load()
+	if p == nil {
+		return nil
+	}
+	return *p
+}
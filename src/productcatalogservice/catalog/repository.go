@@ -0,0 +1,23 @@
+// Package catalog holds the product catalog's storage backends behind a Repository interface, so
+// productcatalogservice doesn't need to know whether the data comes from a local JSON file, a GCS
+// bucket, or a SQL table.
+package catalog
+
+import (
+	"context"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/genproto"
+)
+
+// Repository is the storage-agnostic interface the gRPC service delegates to.
+type Repository interface {
+	List(ctx context.Context) ([]*pb.Product, error)
+	Get(ctx context.Context, id string) (*pb.Product, error)
+	Search(ctx context.Context, query string) ([]*pb.Product, error)
+}
+
+// Reloadable is implemented by repositories that can refresh their data without a process
+// restart (File, GCS). SQL doesn't need it since every query already hits the live table.
+type Reloadable interface {
+	Reload(ctx context.Context) error
+}
@@ -0,0 +1,96 @@
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	pb "github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/genproto"
+)
+
+// SQLRepository serves the catalog from a `products` table (Postgres or SQLite via database/sql,
+// selected by the driver registered with the *sql.DB the caller passes in). Unlike File/GCS it
+// implements no Reload: every call already hits the live table.
+type SQLRepository struct {
+	db *sql.DB
+}
+
+// NewSQLRepository wraps an already-opened *sql.DB. The caller owns the connection's lifecycle.
+func NewSQLRepository(db *sql.DB) *SQLRepository {
+	return &SQLRepository{db: db}
+}
+
+const productColumns = "id, name, description, picture, price_currency_code, price_units, price_nanos, categories"
+
+func (r *SQLRepository) List(ctx context.Context) ([]*pb.Product, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+productColumns+" FROM products")
+	if err != nil {
+		return nil, errors.Wrap(err, "catalog: failed to list products")
+	}
+	defer rows.Close()
+	return scanProducts(rows)
+}
+
+func (r *SQLRepository) Get(ctx context.Context, id string) (*pb.Product, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+productColumns+" FROM products WHERE id = $1", id)
+	p, err := scanProduct(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Errorf("no product with ID %s", id)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "catalog: failed to get product %s", id)
+	}
+	return p, nil
+}
+
+// Search runs a case-insensitive LIKE across name and description, lowercasing both sides so it
+// behaves the same on Postgres (where plain LIKE is case-sensitive) and SQLite.
+func (r *SQLRepository) Search(ctx context.Context, query string) ([]*pb.Product, error) {
+	like := "%" + query + "%"
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+productColumns+" FROM products WHERE LOWER(name) LIKE LOWER($1) OR LOWER(description) LIKE LOWER($1)", like)
+	if err != nil {
+		return nil, errors.Wrap(err, "catalog: failed to search products")
+	}
+	defer rows.Close()
+	return scanProducts(rows)
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanProduct(row rowScanner) (*pb.Product, error) {
+	var (
+		p            pb.Product
+		currencyCode string
+		units        int64
+		nanos        int32
+		categoriesCS string
+	)
+	p.PriceUsd = &pb.Money{}
+	if err := row.Scan(&p.Id, &p.Name, &p.Description, &p.Picture, &currencyCode, &units, &nanos, &categoriesCS); err != nil {
+		return nil, err
+	}
+	p.PriceUsd.CurrencyCode = currencyCode
+	p.PriceUsd.Units = units
+	p.PriceUsd.Nanos = nanos
+	if categoriesCS != "" {
+		p.Categories = strings.Split(categoriesCS, ",")
+	}
+	return &p, nil
+}
+
+func scanProducts(rows *sql.Rows) ([]*pb.Product, error) {
+	var products []*pb.Product
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "catalog: failed to scan product row")
+		}
+		products = append(products, p)
+	}
+	return products, rows.Err()
+}
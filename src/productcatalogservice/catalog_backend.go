@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+
+	// Registers the postgres and sqlite drivers for CATALOG_BACKEND=sql.
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+
+	"github.com/GoogleCloudPlatform/microservices-demo/src/productcatalogservice/catalog"
+)
+
+const catalogJSONFile = "products.json"
+
+// newCatalogRepository builds the catalog.Repository selected by CATALOG_BACKEND (file, gcs, or
+// sql; defaults to file). The second return value is the local file path to fsnotify-watch for
+// hot reload, and is only non-empty for the file backend.
+func newCatalogRepository() (catalog.Repository, string, error) {
+	switch backend := os.Getenv("CATALOG_BACKEND"); backend {
+	case "", "file":
+		repo, err := catalog.NewFileRepository(catalogJSONFile)
+		if err != nil {
+			return nil, "", err
+		}
+		return repo, catalogJSONFile, nil
+
+	case "gcs":
+		bucket := os.Getenv("CATALOG_GCS_BUCKET")
+		object := os.Getenv("CATALOG_GCS_OBJECT")
+		if object == "" {
+			object = "products.json"
+		}
+		repo, err := catalog.NewGCSRepository(context.Background(), bucket, object)
+		if err != nil {
+			return nil, "", err
+		}
+		return repo, "", nil
+
+	case "sql":
+		driver := os.Getenv("CATALOG_SQL_DRIVER")
+		if driver == "" {
+			driver = "sqlite"
+		}
+		db, err := sql.Open(driver, os.Getenv("CATALOG_SQL_DSN"))
+		if err != nil {
+			return nil, "", err
+		}
+		return catalog.NewSQLRepository(db), "", nil
+
+	default:
+		return nil, "", fmt.Errorf("unknown CATALOG_BACKEND %q (want file, gcs, or sql)", backend)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// startHealthzServer starts a lightweight HTTP sidecar (separate from the gRPC health service)
+// exposing what Kubernetes probes actually need: /healthz always returns 200 once the process is
+// up (liveness), and /readyz returns 503 until the first catalog load has succeeded and again
+// while a reload is in progress (readiness). Returns nil if HEALTH_PORT is unset.
+func startHealthzServer(svc *productCatalog) *http.Server {
+	healthPort := os.Getenv("HEALTH_PORT")
+	if healthPort == "" {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+		if !svc.ready.Load() {
+			http.Error(w, "catalog not yet loaded", http.StatusServiceUnavailable)
+			return
+		}
+		if svc.reloading.Load() {
+			http.Error(w, "catalog reload in progress", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "ready")
+	})
+
+	srv := &http.Server{Addr: ":" + healthPort, Handler: mux}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Warn("healthz sidecar server stopped unexpectedly")
+		}
+	}()
+	log.Infof("healthz sidecar listening on :%s (/healthz, /readyz)", healthPort)
+	return srv
+}
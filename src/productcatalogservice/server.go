@@ -1,12 +1,12 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"net"
 	"os"
 	"os/signal"
-	"sync"
 	"syscall"
 	"time"
 
@@ -20,16 +20,16 @@ import (
 	// Datadog native tracing
 	grpctrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/google.golang.org/grpc"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+
+	// OpenTelemetry tracing, run alongside or instead of Datadog depending on TRACING_PROVIDER.
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 )
 
 var (
-	catalogMutex *sync.Mutex
 	log          *logrus.Logger
 	extraLatency time.Duration
 
 	port = "3550"
-
-	reloadCatalog bool
 )
 
 func init() {
@@ -43,13 +43,20 @@ func init() {
 		TimestampFormat: time.RFC3339Nano,
 	}
 	log.Out = os.Stdout
-	catalogMutex = &sync.Mutex{}
 }
 
 func main() {
+	provider := tracingProvider()
 	if os.Getenv("ENABLE_TRACING") == "1" {
-		initTracing()
-		defer tracer.Stop()
+		if provider == "datadog" || provider == "both" {
+			initTracing()
+		}
+		if provider == "otel" || provider == "both" {
+			if err := initOtelTracing(); err != nil {
+				log.WithError(err).Warn("failed to start OpenTelemetry tracer, continuing without it")
+			}
+		}
+		log.Infof("Tracing enabled (provider: %s).", provider)
 	} else {
 		log.Info("Tracing disabled.")
 	}
@@ -75,52 +82,101 @@ func main() {
 		extraLatency = time.Duration(0)
 	}
 
+	if os.Getenv("PORT") != "" {
+		port = os.Getenv("PORT")
+	}
+	log.Infof("starting grpc server at :%s", port)
+	_, srv, reloader, svc := run(port)
+	healthzSrv := startHealthzServer(svc)
+
+	// SIGUSR1/SIGUSR2 used to toggle a per-request reload flag; they now force an immediate
+	// reload instead, same as the fsnotify/poll triggers in reloader.
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGUSR1, syscall.SIGUSR2)
 	go func() {
-		for {
-			sig := <-sigs
-			log.Printf("Received signal: %s", sig)
-			if sig == syscall.SIGUSR1 {
-				reloadCatalog = true
-				log.Infof("Enable catalog reloading")
-			} else {
-				reloadCatalog = false
-				log.Infof("Disable catalog reloading")
+		for sig := range sigs {
+			log.Infof("received signal %s, forcing catalog reload", sig)
+			if err := reloader.forceReload(); err != nil {
+				log.WithError(err).Warn("manual catalog reload failed")
 			}
 		}
 	}()
 
-	if os.Getenv("PORT") != "" {
-		port = os.Getenv("PORT")
+	// Block until a termination signal arrives, then flush whichever tracer(s) are active. Prior
+	// to this, main() ended in select{} forever, so the tracer.Stop() it deferred never ran.
+	term := make(chan os.Signal, 1)
+	signal.Notify(term, syscall.SIGTERM, syscall.SIGINT)
+	sig := <-term
+	log.Infof("received signal %s, shutting down", sig)
+
+	// Flip the health service to NOT_SERVING first so the load balancer stops routing new
+	// requests, then give in-flight RPCs a grace period before forcing the issue with GracefulStop.
+	svc.setServing(false)
+	gracePeriod := 10 * time.Second
+	if s := os.Getenv("SHUTDOWN_GRACE_PERIOD"); s != "" {
+		if d, err := time.ParseDuration(s); err == nil {
+			gracePeriod = d
+		}
+	}
+	time.Sleep(gracePeriod)
+
+	reloader.Stop()
+	srv.GracefulStop()
+	if healthzSrv != nil {
+		_ = healthzSrv.Close()
+	}
+	if provider == "datadog" || provider == "both" {
+		tracer.Stop()
+	}
+	if provider == "otel" || provider == "both" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		shutdownOtelTracing(ctx)
+		cancel()
 	}
-	log.Infof("starting grpc server at :%s", port)
-	run(port)
-	select {}
 }
 
-func run(port string) string {
+func run(port string) (string, *grpc.Server, *catalogReloader, *productCatalog) {
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", port))
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	// Create gRPC server with Datadog tracing interceptors
+	// Build the interceptor chain from whichever tracing provider(s) are active. ChainUnaryInterceptor
+	// lets Datadog and OTel each wrap the handler once, so with TRACING_PROVIDER=both spans are
+	// duplicated to both backends without either one seeing the handler invoked twice.
+	provider := tracingProvider()
+	var unary []grpc.UnaryServerInterceptor
+	var stream []grpc.StreamServerInterceptor
+	if provider == "datadog" || provider == "both" {
+		unary = append(unary, grpctrace.UnaryServerInterceptor())
+		stream = append(stream, grpctrace.StreamServerInterceptor())
+	}
+	if provider == "otel" || provider == "both" {
+		unary = append(unary, otelgrpc.UnaryServerInterceptor())
+		stream = append(stream, otelgrpc.StreamServerInterceptor())
+	}
+
 	srv := grpc.NewServer(
-		grpc.UnaryInterceptor(grpctrace.UnaryServerInterceptor()),
-		grpc.StreamInterceptor(grpctrace.StreamServerInterceptor()))
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...))
 
-	svc := &productCatalog{}
-	err = loadCatalog(&svc.catalog)
+	repo, watchPath, err := newCatalogRepository()
 	if err != nil {
-		log.Fatalf("could not parse product catalog: %v", err)
+		log.Fatalf("could not initialize catalog repository: %v", err)
+	}
+	svc := newProductCatalog(repo)
+
+	reloader := newCatalogReloader(svc, watchPath)
+	if err := reloader.Start(); err != nil {
+		log.WithError(err).Warn("catalog reload subsystem failed to start, falling back to startup catalog only")
 	}
 
+	svc.setServing(true)
 	pb.RegisterProductCatalogServiceServer(srv, svc)
 	healthpb.RegisterHealthServer(srv, svc)
 	go srv.Serve(listener)
 
-	return listener.Addr().String()
+	return listener.Addr().String(), srv, reloader, svc
 }
 
 func initStats() {